@@ -0,0 +1,128 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// TokenOption configures how CreateTokenWithOptions builds and signs a token
+type TokenOption func(*tokenOptions)
+
+type tokenOptions struct {
+	algorithm string
+	notBefore time.Duration
+	keyID     string
+	header    map[string]interface{}
+}
+
+// WithAlgorithm selects the signing algorithm (e.g. "RS256", "PS384", "ES256", "EdDSA" or "HS512").
+// The algorithm must match the type of the signing key.
+func WithAlgorithm(alg string) TokenOption {
+	return func(o *tokenOptions) {
+		o.algorithm = alg
+	}
+}
+
+// WithKeyID sets the "kid" header of the token so validators can select the right key
+func WithKeyID(kid string) TokenOption {
+	return func(o *tokenOptions) {
+		o.keyID = kid
+	}
+}
+
+// WithHeader sets an additional header field of the token, e.g. "cty" or "x5t".
+// The "alg" header can not be set this way, use WithAlgorithm instead.
+func WithHeader(name string, value interface{}) TokenOption {
+	return func(o *tokenOptions) {
+		if o.header == nil {
+			o.header = map[string]interface{}{}
+		}
+		o.header[name] = value
+	}
+}
+
+// WithType sets the "typ" header of the token, e.g. "at+jwt" for access tokens (RFC 9068)
+func WithType(typ string) TokenOption {
+	return WithHeader("typ", typ)
+}
+
+// signingMethodForKey returns the signing method for the requested algorithm and
+// makes sure it can be used with the given private key. If alg is empty the
+// default algorithm for the key type is used.
+func signingMethodForKey(key interface{}, alg string) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return signingMethodForPublicKey(&k.PublicKey, alg)
+	case *ecdsa.PrivateKey:
+		return signingMethodForPublicKey(&k.PublicKey, alg)
+	case ed25519.PrivateKey:
+		return signingMethodForPublicKey(k.Public(), alg)
+	case []byte:
+		if alg == "" {
+			return jwt.SigningMethodHS512, nil
+		}
+		if m, ok := jwt.GetSigningMethod(alg).(*jwt.SigningMethodHMAC); ok {
+			return m, nil
+		}
+		return nil, fmt.Errorf("algorithm %v can not be used with a %T key: %w", alg, key, ErrAlgorithmNotAllowed)
+	case crypto.Signer:
+		// e.g. keys held by a KMS or HSM, the algorithm is derived from the public key
+		m, err := signingMethodForPublicKey(k.Public(), alg)
+		if err != nil {
+			return nil, err
+		}
+		return signerMethod{m}, nil
+	}
+	return nil, fmt.Errorf("invalid private key: %w", ErrKeyTypeUnsupported)
+}
+
+// signingMethodForPublicKey returns the signing method for the requested algorithm and
+// makes sure it matches the public key of the signing key
+func signingMethodForPublicKey(key crypto.PublicKey, alg string) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg == "" {
+			return jwt.SigningMethodRS512, nil
+		}
+		switch alg {
+		case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+			return jwt.GetSigningMethod(alg), nil
+		}
+	case *ecdsa.PublicKey:
+		if alg == "" {
+			return ecdsaSigningMethod(k)
+		}
+		if m, ok := jwt.GetSigningMethod(alg).(*jwt.SigningMethodECDSA); ok {
+			if m.CurveBits != k.Curve.Params().BitSize {
+				return nil, fmt.Errorf("algorithm %v can not be used with a %v key: %w", alg, k.Curve.Params().Name, ErrAlgorithmNotAllowed)
+			}
+			return m, nil
+		}
+	case ed25519.PublicKey:
+		if alg == "" || alg == jwt.SigningMethodEdDSA.Alg() {
+			return jwt.SigningMethodEdDSA, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid private key: %w", ErrKeyTypeUnsupported)
+	}
+	return nil, fmt.Errorf("algorithm %v can not be used with a %T key: %w", alg, key, ErrAlgorithmNotAllowed)
+}
+
+// ecdsaSigningMethod returns the only ES algorithm which is valid for the curve of the key
+func ecdsaSigningMethod(key *ecdsa.PublicKey) (*jwt.SigningMethodECDSA, error) {
+	switch key.Curve.Params().BitSize {
+	case 256:
+		return jwt.SigningMethodES256, nil
+	case 384:
+		return jwt.SigningMethodES384, nil
+	case 521:
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("unsupported ecdsa curve %v: %w", key.Curve.Params().Name, ErrKeyTypeUnsupported)
+}
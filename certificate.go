@@ -0,0 +1,125 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// ErrCertificateInvalid is returned when a certificate can not be verified against the trusted roots
+var ErrCertificateInvalid = errors.New("certificate is invalid")
+
+// LoadVerifiedPublicKey loads a PEM encoded certificate chain and returns the public key of the
+// first certificate if the chain can be verified, see ParseVerifiedPublicKey.
+func LoadVerifiedPublicKey(certFile string, opts x509.VerifyOptions) (interface{}, error) {
+	bs, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVerifiedPublicKey(bs, opts)
+}
+
+// ParseVerifiedPublicKey parses a PEM encoded certificate chain and returns the public key of the
+// first certificate. The certificate is verified with opts before its key is trusted: it must chain
+// up to opts.Roots (the system roots if nil) and be valid at opts.CurrentTime (now if zero). The
+// other certificates of the data are used as intermediates. Unlike x509.Verify any extended key
+// usage is accepted unless opts.KeyUsages is set, a key usage extension must allow digital signatures.
+func ParseVerifiedPublicKey(data []byte, opts x509.VerifyOptions) (interface{}, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, &KeyFormatError{Format: pemFormats["CERTIFICATE"].name, Err: err}
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: expected a certificate", ErrNoPEMData)
+	}
+	return verifyCertificateChain(chain, opts)
+}
+
+// X5CKeys returns a KeyProvider which takes the key from the certificate chain in the "x5c" header
+// of the token. The chain is verified with opts like in ParseVerifiedPublicKey, so only tokens signed
+// by keys certified by opts.Roots are accepted.
+func X5CKeys(opts x509.VerifyOptions) KeyProvider {
+	return x5cKeys{opts}
+}
+
+type x5cKeys struct {
+	opts x509.VerifyOptions
+}
+
+func (k x5cKeys) Keys(header map[string]interface{}, _ Claims) ([]interface{}, error) {
+	values, _ := header["x5c"].([]interface{})
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: token has no x5c header", ErrCertificateInvalid)
+	}
+	chain := make([]*x509.Certificate, len(values))
+	for i, value := range values {
+		encoded, _ := value.(string)
+		// x5c uses standard base64, not base64url
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: x5c entry %v is not base64 encoded", ErrCertificateInvalid, i)
+		}
+		if chain[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("%w: x5c entry %v: %v", ErrCertificateInvalid, i, err)
+		}
+	}
+	key, err := verifyCertificateChain(chain, k.opts)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{key}, nil
+}
+
+// WithCertificateChain sets the "x5c" header of the token to the given chain, starting with the
+// certificate of the signing key
+func WithCertificateChain(chain ...*x509.Certificate) TokenOption {
+	x5c := make([]string, len(chain))
+	for i, cert := range chain {
+		x5c[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+	return WithHeader("x5c", x5c)
+}
+
+// verifyCertificateChain verifies the first certificate of the chain, the others are used as
+// intermediates. If the chain consists of one certificate opts.Intermediates is used.
+func verifyCertificateChain(chain []*x509.Certificate, opts x509.VerifyOptions) (interface{}, error) {
+	leaf := chain[0]
+	if len(chain) > 1 {
+		opts.Intermediates = x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+	if len(opts.KeyUsages) == 0 {
+		opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCertificateInvalid, err)
+	}
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, fmt.Errorf("%w: the key usage does not allow digital signatures", ErrCertificateInvalid)
+	}
+	switch leaf.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return leaf.PublicKey, nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrKeyTypeUnsupported, leaf.PublicKey)
+}
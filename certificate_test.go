@@ -0,0 +1,130 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certificates", func() {
+
+	var (
+		root, intermediate, leaf *x509.Certificate
+		rootKey, leafKey         *ecdsa.PrivateKey
+		opts                     x509.VerifyOptions
+	)
+
+	// newCertificate creates a certificate signed by parent, a nil parent creates a self signed certificate
+	newCertificate := func(name string, ca bool, usage x509.KeyUsage, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+		Expect(err).NotTo(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          serial,
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              usage,
+			BasicConstraintsValid: true,
+			IsCA:                  ca,
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return cert, key
+	}
+
+	encode := func(certs ...*x509.Certificate) []byte {
+		var data []byte
+		for _, cert := range certs {
+			data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return data
+	}
+
+	BeforeEach(func() {
+		var intermediateKey *ecdsa.PrivateKey
+		root, rootKey = newCertificate("root", true, x509.KeyUsageCertSign, nil, nil)
+		intermediate, intermediateKey = newCertificate("intermediate", true, x509.KeyUsageCertSign, root, rootKey)
+		leaf, leafKey = newCertificate("leaf", false, x509.KeyUsageDigitalSignature, intermediate, intermediateKey)
+		opts = x509.VerifyOptions{Roots: x509.NewCertPool()}
+		opts.Roots.AddCert(root)
+	})
+
+	It("should return the key of a verified certificate chain", func() {
+		key, err := ParseVerifiedPublicKey(encode(leaf, intermediate), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*ecdsa.PublicKey).Equal(&leafKey.PublicKey)).To(BeTrue())
+	})
+
+	It("should reject certificates of other roots", func() {
+		otherRoot, otherKey := newCertificate("other", true, x509.KeyUsageCertSign, nil, nil)
+		otherLeaf, _ := newCertificate("leaf", false, x509.KeyUsageDigitalSignature, otherRoot, otherKey)
+		_, err := ParseVerifiedPublicKey(encode(otherLeaf, otherRoot), opts)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+
+		// the intermediate is required to build the chain
+		_, err = ParseVerifiedPublicKey(encode(leaf), opts)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+	})
+
+	It("should check the validity window", func() {
+		opts.CurrentTime = time.Now().Add(2 * time.Hour)
+		_, err := ParseVerifiedPublicKey(encode(leaf, intermediate), opts)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+	})
+
+	It("should check the key usage", func() {
+		encipherOnly, _ := newCertificate("leaf", false, x509.KeyUsageKeyEncipherment, root, rootKey)
+		_, err := ParseVerifiedPublicKey(encode(encipherOnly), opts)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("digital signatures")))
+	})
+
+	It("should verify tokens with a x5c header", func() {
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, leafKey, WithCertificateChain(leaf, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+		claims, err := NewValidator(X5CKeys(opts)).Validate(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims["foo"]).To(Equal("bar"))
+
+		// the chain does not certify the signing key
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		token, err = CreateTokenWithOptions(Claims{"foo": "bar"}, otherKey, WithCertificateChain(leaf, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewValidator(X5CKeys(opts)).Validate(token)
+		Expect(errors.Is(err, ErrSignatureInvalid)).To(BeTrue())
+	})
+
+	It("should reject tokens without a trusted x5c header", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, leafKey)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewValidator(X5CKeys(opts)).Validate(token)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+
+		token, err = CreateTokenWithOptions(Claims{"foo": "bar"}, leafKey, WithCertificateChain(leaf))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewValidator(X5CKeys(opts)).Validate(token)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+
+		token, err = CreateTokenWithOptions(Claims{"foo": "bar"}, leafKey, WithHeader("x5c", []string{"not base64!"}))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = NewValidator(X5CKeys(opts)).Validate(token)
+		Expect(errors.Is(err, ErrCertificateInvalid)).To(BeTrue())
+	})
+})
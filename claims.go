@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Subject returns the "sub" claim or an empty string
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the "iss" claim or an empty string
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// ID returns the "jti" claim or an empty string
+func (c Claims) ID() string {
+	s, _ := c["jti"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which can be a single string or an array of strings
+func (c Claims) Audience() []string {
+	aud, _ := stringOrStrings(c["aud"])
+	return aud
+}
+
+// ExpiresAt returns the "exp" claim or the zero time if it is not set
+func (c Claims) ExpiresAt() time.Time {
+	t, _, _ := timeClaim(c, "exp")
+	return t
+}
+
+// NotBefore returns the "nbf" claim or the zero time if it is not set
+func (c Claims) NotBefore() time.Time {
+	t, _, _ := timeClaim(c, "nbf")
+	return t
+}
+
+// IssuedAt returns the "iat" claim or the zero time if it is not set
+func (c Claims) IssuedAt() time.Time {
+	t, _, _ := timeClaim(c, "iat")
+	return t
+}
+
+// Has reports whether the claim is set
+func (c Claims) Has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+// GetString returns a string claim
+func (c Claims) GetString(name string) (string, error) {
+	value, ok := c[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrClaimMissing, name)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", claimTypeError(name, value, "a string")
+	}
+	return s, nil
+}
+
+// GetStringSlice returns a claim which is an array of strings. A single string is returned as slice
+// with one element.
+func (c Claims) GetStringSlice(name string) ([]string, error) {
+	value, ok := c[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrClaimMissing, name)
+	}
+	s, ok := stringOrStrings(value)
+	if !ok {
+		return nil, claimTypeError(name, value, "an array of strings")
+	}
+	return s, nil
+}
+
+// GetInt64 returns an integer claim. Numbers decoded as float64 or json.Number are converted if
+// they are integral and fit into an int64.
+func (c Claims) GetInt64(name string) (int64, error) {
+	value, ok := c[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrClaimMissing, name)
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), nil
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	}
+	return 0, claimTypeError(name, value, "an integer")
+}
+
+// GetFloat64 returns a numeric claim
+func (c Claims) GetFloat64(name string) (float64, error) {
+	value, ok := c[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrClaimMissing, name)
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	case int64:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	}
+	return 0, claimTypeError(name, value, "a number")
+}
+
+// GetBool returns a boolean claim
+func (c Claims) GetBool(name string) (bool, error) {
+	value, ok := c[name]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrClaimMissing, name)
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, claimTypeError(name, value, "a boolean")
+	}
+	return b, nil
+}
+
+// claimTypeError describes a claim which has not the expected type
+func claimTypeError(name string, value interface{}, expected string) error {
+	return fmt.Errorf("%w: claim %q is %v, not %v", ErrClaimsInvalid, name, jsonType(value), expected)
+}
+
+// jsonType returns the json type of a decoded value for error messages
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64, json.Number, int, int64:
+		return fmt.Sprintf("the number %v", v)
+	case []interface{}, []string:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("a %T", value)
+}
@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Claims", func() {
+
+	It("should return the registered claims", func() {
+		now := time.Now().Truncate(time.Second)
+		claims := Claims{
+			"sub": "user",
+			"iss": "idp",
+			"jti": "id",
+			"aud": []interface{}{"api", "web"},
+			"exp": float64(now.Add(time.Hour).Unix()),
+			"iat": json.Number(fmt.Sprint(now.Unix())),
+		}
+		Expect(claims.Subject()).To(Equal("user"))
+		Expect(claims.Issuer()).To(Equal("idp"))
+		Expect(claims.ID()).To(Equal("id"))
+		Expect(claims.Audience()).To(Equal([]string{"api", "web"}))
+		Expect(claims.ExpiresAt()).To(Equal(now.Add(time.Hour)))
+		Expect(claims.IssuedAt()).To(Equal(now))
+		Expect(claims.NotBefore().IsZero()).To(BeTrue())
+
+		claims["aud"] = "api"
+		Expect(claims.Audience()).To(Equal([]string{"api"}))
+		Expect(Claims{}.Subject()).To(BeEmpty())
+	})
+
+	It("should convert numbers to int64", func() {
+		claims := Claims{
+			"float":    float64(42),
+			"number":   json.Number("9007199254740993"),
+			"exponent": json.Number("1e3"),
+			"int":      int64(7),
+			"fraction": 1.5,
+			"string":   "42",
+		}
+		for name, expected := range map[string]int64{"float": 42, "number": 9007199254740993, "exponent": 1000, "int": 7} {
+			i, err := claims.GetInt64(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(i).To(Equal(expected))
+		}
+		_, err := claims.GetInt64("fraction")
+		Expect(errors.Is(err, ErrClaimsInvalid)).To(BeTrue())
+		_, err = claims.GetInt64("string")
+		Expect(err).To(MatchError(ContainSubstring(`claim "string" is a string, not an integer`)))
+		_, err = claims.GetInt64("missing")
+		Expect(errors.Is(err, ErrClaimMissing)).To(BeTrue())
+	})
+
+	It("should return typed claims with clear errors", func() {
+		claims := Claims{"name": "foo", "roles": []interface{}{"admin", "user"}, "admin": true, "count": float64(3)}
+		Expect(claims.Has("name")).To(BeTrue())
+		Expect(claims.Has("missing")).To(BeFalse())
+
+		s, err := claims.GetString("name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal("foo"))
+		_, err = claims.GetString("count")
+		Expect(err).To(MatchError(ContainSubstring(`claim "count" is the number 3, not a string`)))
+
+		roles, err := claims.GetStringSlice("roles")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roles).To(Equal([]string{"admin", "user"}))
+		roles, err = claims.GetStringSlice("name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roles).To(Equal([]string{"foo"}))
+		_, err = claims.GetStringSlice("admin")
+		Expect(errors.Is(err, ErrClaimsInvalid)).To(BeTrue())
+
+		b, err := claims.GetBool("admin")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(BeTrue())
+		_, err = claims.GetBool("roles")
+		Expect(err).To(MatchError(ContainSubstring(`claim "roles" is an array, not a boolean`)))
+		_, err = claims.GetBool("missing")
+		Expect(errors.Is(err, ErrClaimMissing)).To(BeTrue())
+	})
+
+	It("should keep the precision of large numbers with UseJSONNumber", func() {
+		token, err := IssueToken(Claims{"id": int64(9007199254740993), "ratio": 0.5}, rsaPriv, time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := GetUnvalidatedClaims(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims["id"]).To(BeAssignableToTypeOf(float64(0)))
+
+		claims, err = GetUnvalidatedClaimsWithJSONNumber(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims["id"]).To(Equal(json.Number("9007199254740993")))
+
+		claims, err = ValidateTokenWithOptions(token, rsaPub, UseJSONNumber(), RequireExpiry())
+		Expect(err).NotTo(HaveOccurred())
+		id, err := claims.GetInt64("id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(Equal(int64(9007199254740993)))
+		ratio, err := claims.GetFloat64("ratio")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ratio).To(Equal(0.5))
+		Expect(claims.ExpiresAt()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+		_, err = claims.GetFloat64("jti")
+		Expect(errors.Is(err, ErrClaimsInvalid)).To(BeTrue())
+
+		expired, err := IssueToken(Claims{"id": int64(9007199254740993)}, rsaPriv, -time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(expired, rsaPub, UseJSONNumber())
+		Expect(errors.Is(err, ErrTokenExpired)).To(BeTrue())
+	})
+})
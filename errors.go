@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"errors"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// The errors returned by this package. They can be matched with errors.Is, the validation
+// errors of the jwt library are wrapped in a ValidationError.
+var (
+	// ErrTokenMissing is returned when a request carries no token
+	ErrTokenMissing = errors.New("no valid authorization header")
+	// ErrTokenMalformed is returned when a token can not be decoded
+	ErrTokenMalformed = errors.New("token is malformed")
+	// ErrTokenUnverifiable is returned when a token could not be verified, e.g. because of an unknown algorithm
+	ErrTokenUnverifiable = errors.New("token could not be verified")
+	// ErrSignatureInvalid is returned when the signature of a token does not match
+	ErrSignatureInvalid = errors.New("token signature is invalid")
+	// ErrTokenExpired is returned when the "exp" claim is in the past
+	ErrTokenExpired = errors.New("token is expired")
+	// ErrTokenNotYetValid is returned when the "nbf" claim is in the future
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	// ErrTokenUsedBeforeIssued is returned when the "iat" claim is in the future
+	ErrTokenUsedBeforeIssued = errors.New("token used before issued")
+	// ErrClaimsInvalid is returned when the claims of a token are invalid for another reason
+	ErrClaimsInvalid = errors.New("token claims are invalid")
+	// ErrClaimMissing is returned by the Claims getters when the claim is not set
+	ErrClaimMissing = errors.New("claim is missing")
+	// ErrKeyTypeUnsupported is returned when a key of an unsupported type is used
+	ErrKeyTypeUnsupported = errors.New("unsupported key type")
+	// ErrInvalidType is returned when the "typ" header of a token does not match the expected type
+	ErrInvalidType = errors.New("token has an unexpected type")
+	// ErrInvalidHeader is returned when a token header can not be set, e.g. the "alg" header
+	ErrInvalidHeader = errors.New("invalid token header")
+	// ErrUnknownKeyID is returned when no key is known for the "kid" header of a token
+	ErrUnknownKeyID = errors.New("unknown key id")
+	// ErrAlgorithmNotAllowed is returned when the "alg" header of a token is not in the list of allowed algorithms
+	ErrAlgorithmNotAllowed = errors.New("signing algorithm is not allowed")
+	// ErrInvalidIssuer is returned when the "iss" claim is missing or not one of the expected issuers
+	ErrInvalidIssuer = errors.New("token has an unexpected issuer")
+	// ErrInvalidAudience is returned when the "aud" claim is missing or does not contain the expected audience
+	ErrInvalidAudience = errors.New("token has an unexpected audience")
+	// ErrMissingSubject is returned when a subject is required but the "sub" claim is missing or empty
+	ErrMissingSubject = errors.New("token has no subject")
+	// ErrMissingExpiry is returned when an expiry is required but the "exp" claim is missing
+	ErrMissingExpiry = errors.New("token has no expiry")
+	// ErrMissingIssuedAt is returned when an issue date is required but the "iat" claim is missing
+	ErrMissingIssuedAt = errors.New("token has no issue date")
+	// ErrLifetimeTooLong is returned when the time between "iat" and "exp" exceeds the maximum lifetime
+	ErrLifetimeTooLong = errors.New("token lifetime exceeds the maximum lifetime")
+	// ErrTokenTooOld is returned when the time since "iat" exceeds the maximum age
+	ErrTokenTooOld = errors.New("token exceeds the maximum age")
+)
+
+// validationErrorKinds maps the error flags of the jwt library to the errors of this package
+var validationErrorKinds = []struct {
+	flag uint32
+	err  error
+}{
+	{jwt.ValidationErrorMalformed, ErrTokenMalformed},
+	{jwt.ValidationErrorUnverifiable, ErrTokenUnverifiable},
+	{jwt.ValidationErrorSignatureInvalid, ErrSignatureInvalid},
+	{jwt.ValidationErrorAudience, ErrInvalidAudience},
+	{jwt.ValidationErrorExpired, ErrTokenExpired},
+	{jwt.ValidationErrorIssuedAt, ErrTokenUsedBeforeIssued},
+	{jwt.ValidationErrorIssuer, ErrInvalidIssuer},
+	{jwt.ValidationErrorNotValidYet, ErrTokenNotYetValid},
+	{jwt.ValidationErrorClaimsInvalid, ErrClaimsInvalid},
+}
+
+// ValidationError wraps the validation error of the jwt library. It matches the errors of
+// this package corresponding to its flags with errors.Is, the original error is available
+// with errors.As.
+type ValidationError struct {
+	// Errors is the bitmask of jwt.ValidationError* flags describing what failed
+	Errors uint32
+	inner  *jwt.ValidationError
+}
+
+// Error returns the message of the wrapped error
+func (e *ValidationError) Error() string {
+	return e.inner.Error()
+}
+
+// Unwrap returns the wrapped *jwt.ValidationError
+func (e *ValidationError) Unwrap() error {
+	return e.inner
+}
+
+// Is reports whether the error flags correspond to target. Errors returned by the key lookup
+// (e.g. ErrAlgorithmNotAllowed) are matched as well.
+func (e *ValidationError) Is(target error) bool {
+	for _, kind := range validationErrorKinds {
+		if e.Errors&kind.flag != 0 && kind.err == target {
+			return true
+		}
+	}
+	return e.inner.Inner != nil && errors.Is(e.inner.Inner, target)
+}
+
+// wrapValidationError wraps errors of the jwt library, other errors are returned as they are
+func wrapValidationError(err error) error {
+	if ve, ok := err.(*jwt.ValidationError); ok {
+		return &ValidationError{Errors: ve.Errors, inner: ve}
+	}
+	return err
+}
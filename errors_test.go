@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+
+	It("should return ErrTokenMissing for requests without token", func() {
+		r, _ := http.NewRequest("GET", "http://foobar.com", nil)
+		_, _, err := GetTokenFromRequest(r, "")
+		Expect(errors.Is(err, ErrTokenMissing)).To(BeTrue())
+	})
+
+	It("should return ErrTokenMalformed for malformed tokens", func() {
+		r, _ := http.NewRequest("GET", "http://foobar.com", nil)
+		r.Header.Add("Authorization", "a b c")
+		_, _, err := GetTokenFromRequest(r, "")
+		Expect(errors.Is(err, ErrTokenMalformed)).To(BeTrue())
+
+		_, err = ValidateToken("wtf-this-is-wrong", rsaPub)
+		Expect(errors.Is(err, ErrTokenMalformed)).To(BeTrue())
+
+		_, err = GetUnvalidatedClaims("header.badtoken")
+		Expect(errors.Is(err, ErrTokenMalformed)).To(BeTrue())
+	})
+
+	It("should return ErrSignatureInvalid for tokens with a bad signature", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, []byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, []byte("other secret"))
+		Expect(errors.Is(err, ErrSignatureInvalid)).To(BeTrue())
+		Expect(errors.Is(err, ErrTokenExpired)).To(BeFalse())
+	})
+
+	It("should return typed errors for time based claims and expose the jwt error flags", func() {
+		token, err := CreateToken(Claims{"exp": 123}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(errors.Is(err, ErrTokenExpired)).To(BeTrue())
+		var validationErr *ValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue())
+		Expect(validationErr.Errors & jwt.ValidationErrorExpired).NotTo(BeZero())
+		var jwtErr *jwt.ValidationError
+		Expect(errors.As(err, &jwtErr)).To(BeTrue())
+
+		token, err = CreateToken(Claims{"nbf": time.Now().Add(time.Hour).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(errors.Is(err, ErrTokenNotYetValid)).To(BeTrue())
+
+		token, err = CreateToken(Claims{"iat": time.Now().Add(time.Hour).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(errors.Is(err, ErrTokenUsedBeforeIssued)).To(BeTrue())
+	})
+
+	It("should return ErrAlgorithmNotAllowed for tokens not matching the key type", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, []byte("secret"))
+		Expect(errors.Is(err, ErrAlgorithmNotAllowed)).To(BeTrue())
+		Expect(errors.Is(err, ErrTokenUnverifiable)).To(BeTrue())
+	})
+
+	It("should return ErrTokenUnverifiable for unknown algorithms", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		parts := strings.Split(swapAlg(token, "XS256"), ".")
+		_, err = ValidateToken(strings.Join(parts, "."), rsaPub)
+		Expect(errors.Is(err, ErrTokenUnverifiable)).To(BeTrue())
+	})
+
+	It("should return ErrKeyTypeUnsupported for unsupported keys", func() {
+		_, err := CreateToken(Claims{"foo": "bar"}, "no string key supported!")
+		Expect(errors.Is(err, ErrKeyTypeUnsupported)).To(BeTrue())
+
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, 123)
+		Expect(errors.Is(err, ErrKeyTypeUnsupported)).To(BeTrue())
+	})
+
+})
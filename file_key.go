@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// FileKey is a KeyProvider backed by a PEM encoded key file. With WithPollInterval the file is
+// watched and reloaded when its content changes, e.g. when a mounted secret gets rotated. If the
+// new content can not be parsed the last good key is kept.
+type FileKey struct {
+	path         string
+	private      bool
+	passphrase   PassphraseFunc
+	pollInterval time.Duration
+	onError      func(error)
+
+	mu   sync.RWMutex
+	key  interface{}
+	data []byte
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// FileKeyOption configures a FileKey
+type FileKeyOption func(*FileKey)
+
+// WithPollInterval makes the FileKey check the file for changes in the given interval
+func WithPollInterval(interval time.Duration) FileKeyOption {
+	return func(k *FileKey) {
+		k.pollInterval = interval
+	}
+}
+
+// WithReloadErrorHandler sets a function which is called with the errors of reloads while watching the file
+func WithReloadErrorHandler(onError func(error)) FileKeyOption {
+	return func(k *FileKey) {
+		k.onError = onError
+	}
+}
+
+// WithPassphrase sets the passphrase to decrypt an encrypted private key file
+func WithPassphrase(passphrase PassphraseFunc) FileKeyOption {
+	return func(k *FileKey) {
+		k.passphrase = passphrase
+	}
+}
+
+// NewFileKey loads the public key from the given file
+func NewFileKey(path string, opts ...FileKeyOption) (*FileKey, error) {
+	return newFileKey(path, false, opts)
+}
+
+// NewPrivateFileKey loads the private key from the given file. Use Key to get the current key for CreateToken.
+func NewPrivateFileKey(path string, opts ...FileKeyOption) (*FileKey, error) {
+	return newFileKey(path, true, opts)
+}
+
+func newFileKey(path string, private bool, opts []FileKeyOption) (*FileKey, error) {
+	k := &FileKey{
+		path:    path,
+		private: private,
+		onError: func(error) {},
+		stop:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	if k.pollInterval > 0 {
+		go k.watch()
+	}
+	return k, nil
+}
+
+// Key returns the current key
+func (k *FileKey) Key() interface{} {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.key
+}
+
+// Keys returns the current key
+func (k *FileKey) Keys(map[string]interface{}, Claims) ([]interface{}, error) {
+	return []interface{}{k.Key()}, nil
+}
+
+// Reload reads the file and replaces the key if the content changed. On errors the current key is kept.
+func (k *FileKey) Reload() error {
+	data, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+	k.mu.RLock()
+	unchanged := k.key != nil && bytes.Equal(data, k.data)
+	k.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	key, err := parsePEMKey(data, k.private, k.passphrase)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = key
+	k.data = data
+	return nil
+}
+
+// Close stops watching the file
+func (k *FileKey) Close() {
+	k.closeOnce.Do(func() {
+		close(k.stop)
+	})
+}
+
+func (k *FileKey) watch() {
+	ticker := time.NewTicker(k.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-k.stop:
+			return
+		case <-ticker.C:
+			if err := k.Reload(); err != nil {
+				k.onError(err)
+			}
+		}
+	}
+}
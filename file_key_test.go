@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileKey", func() {
+
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "jwt-file-key")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "key.pem")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("should reload the public key when the file changes", func() {
+		Expect(ioutil.WriteFile(path, rsaPubKey, 0644)).To(Succeed())
+		var reloadErrors int32
+		key, err := NewFileKey(path,
+			WithPollInterval(10*time.Millisecond),
+			WithReloadErrorHandler(func(error) { atomic.AddInt32(&reloadErrors, 1) }))
+		Expect(err).NotTo(HaveOccurred())
+		defer key.Close()
+		Expect(key.Key()).To(BeAssignableToTypeOf(&rsa.PublicKey{}))
+
+		Expect(ioutil.WriteFile(path, ecdsaPubKey, 0644)).To(Succeed())
+		Eventually(key.Key).Should(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+
+		ecPriv, err := ParsePrivateKey(ecdsaPrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		token, err := CreateToken(Claims{"foo": "bar"}, ecPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, key)
+		Expect(err).NotTo(HaveOccurred())
+
+		// invalid content keeps the last good key
+		Expect(ioutil.WriteFile(path, []byte("garbage"), 0644)).To(Succeed())
+		Eventually(func() int32 { return atomic.LoadInt32(&reloadErrors) }).Should(BeNumerically(">", 0))
+		Expect(key.Key()).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+		_, err = ValidateToken(token, key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reload the private key on demand", func() {
+		Expect(ioutil.WriteFile(path, rsaPrivKey, 0644)).To(Succeed())
+		key, err := NewPrivateFileKey(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.Key()).To(BeAssignableToTypeOf(&rsa.PrivateKey{}))
+
+		Expect(ioutil.WriteFile(path, ecdsaPrivKey, 0644)).To(Succeed())
+		Expect(key.Reload()).To(Succeed())
+		Expect(key.Key()).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+		_, err = CreateToken(Claims{"foo": "bar"}, key.Key())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Remove(path)).To(Succeed())
+		Expect(key.Reload()).NotTo(Succeed())
+		Expect(key.Key()).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+	})
+
+	It("should decrypt an encrypted private key file", func() {
+		Expect(ioutil.WriteFile(path, scryptPKCS8Key, 0600)).To(Succeed())
+		_, err := NewPrivateFileKey(path)
+		Expect(errors.Is(err, ErrKeyEncrypted)).To(BeTrue())
+
+		key, err := NewPrivateFileKey(path, WithPassphrase(Passphrase("secret")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.Key()).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+	})
+
+	It("should NOT be possible to create a FileKey from an invalid file", func() {
+		Expect(ioutil.WriteFile(path, []byte("garbage"), 0644)).To(Succeed())
+		_, err := NewFileKey(path)
+		Expect(err).To(HaveOccurred())
+		_, err = NewPrivateFileKey(path)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -1,6 +1,6 @@
 module github.com/contiamo/jwt
 
-go 1.12
+go 1.15
 
 require (
 	github.com/golang-jwt/jwt v3.2.2+incompatible
@@ -8,6 +8,7 @@ require (
 	github.com/onsi/ginkgo v1.4.0
 	github.com/onsi/gomega v1.3.0
 	github.com/sirupsen/logrus v1.8.1
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	golang.org/x/net v0.0.0-20190813141303-74dc4d7220e7 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
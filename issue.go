@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// WithNotBefore sets the "nbf" claim of tokens created with IssueToken relative to the issue time
+func WithNotBefore(offset time.Duration) TokenOption {
+	return func(o *tokenOptions) {
+		o.notBefore = offset
+	}
+}
+
+// IssueToken creates a signed token which is valid for ttl. It sets the "iat", "nbf" and "exp"
+// claims, claims which already contain one of them are rejected with ErrClaimsInvalid. A random
+// "jti" claim is set unless it is already present in claims. The passed claims are not modified.
+func IssueToken(claims Claims, key interface{}, ttl time.Duration, opts ...TokenOption) (string, error) {
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		if claims.Has(name) {
+			return "", fmt.Errorf("%w: %q is set by IssueToken", ErrClaimsInvalid, name)
+		}
+	}
+	options := &tokenOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	issued := Claims{
+		"iat": now.Unix(),
+		"nbf": now.Add(options.notBefore).Unix(),
+		"exp": now.Add(ttl).Unix(),
+		"jti": jti,
+	}
+	for k, v := range claims {
+		issued[k] = v
+	}
+	return CreateTokenWithOptions(issued, key, opts...)
+}
+
+// newTokenID returns a random 128 bit token id
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
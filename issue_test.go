@@ -0,0 +1,67 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IssueToken", func() {
+
+	It("should fill in iat, nbf, exp and jti", func() {
+		claims := Claims{"foo": "bar"}
+		before := time.Now().Unix()
+		token, err := IssueToken(claims, []byte("secret"), time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims).To(Equal(Claims{"foo": "bar"}))
+
+		reClaims, err := ValidateTokenWithOptions(token, []byte("secret"), RequireExpiry(), RequireIssuedAt())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(HaveKeyWithValue("foo", "bar"))
+		iat := int64(reClaims["iat"].(float64))
+		Expect(iat).To(BeNumerically(">=", before))
+		Expect(reClaims["nbf"]).To(Equal(reClaims["iat"]))
+		Expect(int64(reClaims["exp"].(float64)) - iat).To(Equal(int64(3600)))
+		Expect(reClaims["jti"]).To(HaveLen(32))
+	})
+
+	It("should generate a unique jti for every token", func() {
+		a, err := IssueToken(Claims{}, []byte("secret"), time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		b, err := IssueToken(Claims{}, []byte("secret"), time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		aClaims, err := GetUnvalidatedClaims(a)
+		Expect(err).NotTo(HaveOccurred())
+		bClaims, err := GetUnvalidatedClaims(b)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(aClaims["jti"]).NotTo(Equal(bClaims["jti"]))
+	})
+
+	It("should apply the not before offset", func() {
+		token, err := IssueToken(Claims{}, []byte("secret"), time.Hour, WithNotBefore(time.Minute), WithAlgorithm("HS256"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, []byte("secret"))
+		Expect(errors.Is(err, ErrTokenNotYetValid)).To(BeTrue())
+		reClaims, err := GetUnvalidatedClaims(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims["nbf"].(float64) - reClaims["iat"].(float64)).To(Equal(float64(60)))
+	})
+
+	It("should keep the jti supplied by the caller", func() {
+		token, err := IssueToken(Claims{"jti": "my-id"}, []byte("secret"), time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		reClaims, err := ValidateToken(token, []byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims["jti"]).To(Equal("my-id"))
+	})
+
+	It("should reject time claims supplied by the caller", func() {
+		for _, name := range []string{"iat", "nbf", "exp"} {
+			_, err := IssueToken(Claims{name: time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)}, []byte("secret"), time.Hour)
+			Expect(errors.Is(err, ErrClaimsInvalid)).To(BeTrue(), name)
+		}
+	})
+
+})
@@ -0,0 +1,395 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// ErrInvalidJWK is returned when a JSON Web Key is malformed or its members do not match
+var ErrInvalidJWK = errors.New("invalid jwk")
+
+// JWK is a JSON Web Key (RFC 7517). Key holds the parsed key in the form CreateToken and
+// ValidateToken accept: *rsa.PublicKey, *rsa.PrivateKey, *ecdsa.PublicKey, *ecdsa.PrivateKey,
+// ed25519.PublicKey, ed25519.PrivateKey or []byte.
+type JWK struct {
+	Key       interface{}
+	KeyID     string
+	Use       string
+	Algorithm string
+	KeyOps    []string
+}
+
+// JWKSet is a JSON Web Key Set
+type JWKSet struct {
+	Keys []*JWK
+}
+
+// rawJWK contains the members of a JWK as they appear in json
+type rawJWK struct {
+	Kty    string   `json:"kty"`
+	Kid    string   `json:"kid,omitempty"`
+	Use    string   `json:"use,omitempty"`
+	Alg    string   `json:"alg,omitempty"`
+	KeyOps []string `json:"key_ops,omitempty"`
+	Crv    string   `json:"crv,omitempty"`
+	N      string   `json:"n,omitempty"`
+	E      string   `json:"e,omitempty"`
+	D      string   `json:"d,omitempty"`
+	P      string   `json:"p,omitempty"`
+	Q      string   `json:"q,omitempty"`
+	Dp     string   `json:"dp,omitempty"`
+	Dq     string   `json:"dq,omitempty"`
+	Qi     string   `json:"qi,omitempty"`
+	X      string   `json:"x,omitempty"`
+	Y      string   `json:"y,omitempty"`
+	K      string   `json:"k,omitempty"`
+}
+
+// errUnknownKeyType is used to skip keys of unknown types in key sets
+var errUnknownKeyType = fmt.Errorf("%w: unknown kty", ErrInvalidJWK)
+
+// ParseJWK parses a single JSON Web Key. Supported key types are RSA, EC (P-256, P-384, P-521),
+// oct and OKP (Ed25519), both public and private keys.
+func ParseJWK(data []byte) (*JWK, error) {
+	var raw rawJWK
+	if err := decodeStrict(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	return raw.toJWK()
+}
+
+// ParseJWKSet parses a JSON Web Key Set. As required by RFC 7517 keys with an unknown key type
+// are ignored, all other keys have to be valid.
+func ParseJWKSet(data []byte) (*JWKSet, error) {
+	var raw struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	if raw.Keys == nil {
+		return nil, fmt.Errorf("%w: missing keys member", ErrInvalidJWK)
+	}
+	set := &JWKSet{}
+	for i, data := range raw.Keys {
+		var rawKey rawJWK
+		if err := json.Unmarshal(data, &rawKey); err != nil {
+			return nil, fmt.Errorf("%w: key %d: %v", ErrInvalidJWK, i, err)
+		}
+		key, err := rawKey.toJWK()
+		if errors.Is(err, errUnknownKeyType) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		set.Keys = append(set.Keys, key)
+	}
+	return set, nil
+}
+
+// KeySet returns the public keys of the set which can be used to verify signatures, indexed
+// by their key id. Keys declaring an "alg" are pinned to it with PinnedKey. Keys without kid,
+// with a use other than "sig", with key_ops lacking "verify" and symmetric (oct) keys are left
+// out, a JWKS document must not provide HMAC secrets.
+func (s *JWKSet) KeySet() KeySet {
+	keys := KeySet{}
+	for _, k := range s.Keys {
+		if k.KeyID == "" || (k.Use != "" && k.Use != "sig") || !k.canVerify() {
+			continue
+		}
+		if _, symmetric := k.Key.([]byte); symmetric {
+			continue
+		}
+		if _, ok := keys[k.KeyID]; ok {
+			continue
+		}
+		var key interface{} = publicKey(k.Key)
+		if k.Algorithm != "" {
+			key = PinnedKey{Key: key, Algorithm: k.Algorithm}
+		}
+		keys[k.KeyID] = key
+	}
+	return keys
+}
+
+// canVerify reports whether the key_ops of the key allow verifying signatures
+func (k *JWK) canVerify() bool {
+	if len(k.KeyOps) == 0 {
+		return true
+	}
+	for _, op := range k.KeyOps {
+		if op == "verify" {
+			return true
+		}
+	}
+	return false
+}
+
+func (raw *rawJWK) toJWK() (*JWK, error) {
+	var (
+		key interface{}
+		err error
+	)
+	switch raw.Kty {
+	case "RSA":
+		key, err = raw.rsaKey()
+	case "EC":
+		key, err = raw.ecdsaKey()
+	case "OKP":
+		key, err = raw.okpKey()
+	case "oct":
+		key, err = raw.octKey()
+	case "":
+		err = errors.New("missing kty member")
+	default:
+		return nil, fmt.Errorf("%w %q", errUnknownKeyType, raw.Kty)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+
+	jwk := &JWK{
+		Key:       key,
+		KeyID:     raw.Kid,
+		Use:       raw.Use,
+		Algorithm: raw.Alg,
+		KeyOps:    raw.KeyOps,
+	}
+	if err := jwk.validateMembers(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWK, err)
+	}
+	return jwk, nil
+}
+
+// validateMembers makes sure that alg, use and key_ops fit to the key and to each other
+func (k *JWK) validateMembers() error {
+	if k.Algorithm != "" {
+		if jwt.GetSigningMethod(k.Algorithm) != nil {
+			var err error
+			if secret, ok := k.Key.([]byte); ok {
+				_, err = signingMethodForKey(secret, k.Algorithm)
+			} else {
+				_, err = signingMethodForPublicKey(publicKey(k.Key), k.Algorithm)
+			}
+			if err != nil {
+				return fmt.Errorf("alg %q does not match the key", k.Algorithm)
+			}
+			if k.Use == "enc" {
+				return fmt.Errorf("signature alg %q does not match use %q", k.Algorithm, k.Use)
+			}
+		} else if k.Use == "sig" {
+			// encryption algorithms are fine for keys not used for signatures
+			return fmt.Errorf("unsupported signature alg %q", k.Algorithm)
+		}
+	}
+	switch k.Use {
+	case "", "sig", "enc":
+	default:
+		return fmt.Errorf("unsupported use %q", k.Use)
+	}
+	seen := map[string]bool{}
+	for _, op := range k.KeyOps {
+		switch op {
+		case "sign", "verify":
+			if k.Use == "enc" {
+				return fmt.Errorf("key_ops %q does not match use %q", op, k.Use)
+			}
+		case "encrypt", "decrypt", "wrapKey", "unwrapKey", "deriveKey", "deriveBits":
+			if k.Use == "sig" {
+				return fmt.Errorf("key_ops %q does not match use %q", op, k.Use)
+			}
+		default:
+			return fmt.Errorf("unsupported key_ops value %q", op)
+		}
+		if seen[op] {
+			return fmt.Errorf("duplicate key_ops value %q", op)
+		}
+		seen[op] = true
+	}
+	return nil
+}
+
+func (raw *rawJWK) rsaKey() (interface{}, error) {
+	n, err := decodeBigInt("n", raw.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt("e", raw.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+	pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+	if raw.D == "" {
+		if raw.P != "" || raw.Q != "" || raw.Dp != "" || raw.Dq != "" || raw.Qi != "" {
+			return nil, errors.New("rsa private key members without d")
+		}
+		return &pub, nil
+	}
+
+	d, err := decodeBigInt("d", raw.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeBigInt("p", raw.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeBigInt("q", raw.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv := &rsa.PrivateKey{PublicKey: pub, D: d, Primes: []*big.Int{p, q}}
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+	priv.Precompute()
+	return priv, nil
+}
+
+func (raw *rawJWK) ecdsaKey() (interface{}, error) {
+	var curve elliptic.Curve
+	switch raw.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported crv %q", raw.Crv)
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	x, err := decodeFixed("x", raw.X, size)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeFixed("y", raw.Y, size)
+	if err != nil {
+		return nil, err
+	}
+	pub := ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	if raw.D == "" {
+		return &pub, nil
+	}
+
+	d, err := decodeFixed("d", raw.D, size)
+	if err != nil {
+		return nil, err
+	}
+	priv := &ecdsa.PrivateKey{PublicKey: pub, D: new(big.Int).SetBytes(d)}
+	if x, y := curve.ScalarBaseMult(d); x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, errors.New("private key does not match the public key")
+	}
+	return priv, nil
+}
+
+func (raw *rawJWK) okpKey() (interface{}, error) {
+	if raw.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported crv %q", raw.Crv)
+	}
+	x, err := decodeFixed("x", raw.X, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if raw.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+	d, err := decodeFixed("d", raw.D, ed25519.SeedSize)
+	if err != nil {
+		return nil, err
+	}
+	priv := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), x) {
+		return nil, errors.New("private key does not match the public key")
+	}
+	return priv, nil
+}
+
+func (raw *rawJWK) octKey() (interface{}, error) {
+	k, err := decodeMember("k", raw.K)
+	if err != nil {
+		return nil, err
+	}
+	if len(k) == 0 {
+		return nil, errors.New("empty k member")
+	}
+	return k, nil
+}
+
+// decodeMember decodes a base64url encoded member without padding
+func decodeMember(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing %v member", name)
+	}
+	bs, err := base64.RawURLEncoding.Strict().DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v member: %v", name, err)
+	}
+	return bs, nil
+}
+
+// decodeFixed decodes a base64url encoded member which has to have the given length
+func decodeFixed(name, value string, size int) ([]byte, error) {
+	bs, err := decodeMember(name, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) != size {
+		return nil, fmt.Errorf("invalid %v member: expected %d bytes, got %d", name, size, len(bs))
+	}
+	return bs, nil
+}
+
+// decodeBigInt decodes a base64url encoded unsigned big endian integer
+func decodeBigInt(name, value string) (*big.Int, error) {
+	bs, err := decodeMember(name, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) > 1 && bs[0] == 0 {
+		return nil, fmt.Errorf("invalid %v member: leading zero", name)
+	}
+	return new(big.Int).SetBytes(bs), nil
+}
+
+// decodeStrict unmarshals json and rejects trailing data
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after jwk")
+	}
+	return nil
+}
+
+// publicKey returns the public part of a private key, other keys are returned as they are
+func publicKey(key interface{}) interface{} {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	}
+	return key
+}
@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// NewJWK wraps a key in a JWK for signatures. The key id is set to the RFC 7638 thumbprint of the key.
+func NewJWK(key interface{}) (*JWK, error) {
+	jwk := &JWK{Key: key, Use: "sig"}
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	jwk.KeyID = kid
+	return jwk, nil
+}
+
+// Public returns a copy of the JWK containing only the public key. For symmetric keys nil is returned.
+func (k *JWK) Public() *JWK {
+	if _, ok := k.Key.([]byte); ok {
+		return nil
+	}
+	public := *k
+	public.Key = publicKey(k.Key)
+	return &public
+}
+
+// Thumbprint returns the base64url encoded SHA-256 JWK thumbprint of the key (RFC 7638)
+func (k *JWK) Thumbprint() (string, error) {
+	raw, err := rawFromKey(publicKey(k.Key))
+	if err != nil {
+		return "", err
+	}
+	// only the required members are part of the thumbprint, the map marshals them in lexicographic order
+	members := map[string]string{"kty": raw.Kty}
+	switch raw.Kty {
+	case "RSA":
+		members["n"], members["e"] = raw.N, raw.E
+	case "EC":
+		members["crv"], members["x"], members["y"] = raw.Crv, raw.X, raw.Y
+	case "OKP":
+		members["crv"], members["x"] = raw.Crv, raw.X
+	case "oct":
+		members["k"] = raw.K
+	}
+	bs, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(bs)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// MarshalJSON encodes the JWK. Private keys are encoded including their private members,
+// use Public to publish verification keys.
+func (k *JWK) MarshalJSON() ([]byte, error) {
+	raw, err := rawFromKey(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	raw.Kid = k.KeyID
+	raw.Use = k.Use
+	raw.Alg = k.Algorithm
+	raw.KeyOps = k.KeyOps
+	return json.Marshal(raw)
+}
+
+// MarshalJSON encodes the JWK Set
+func (s *JWKSet) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []*JWK{}
+	}
+	return json.Marshal(struct {
+		Keys []*JWK `json:"keys"`
+	}{keys})
+}
+
+// JWKSHandler returns a http handler serving a JWK Set document (e.g. at /.well-known/jwks.json)
+// with the public keys of the given keys. Keys can either be *JWK or any key supported by NewJWK.
+func JWKSHandler(keys ...interface{}) (http.Handler, error) {
+	set := &JWKSet{}
+	for _, key := range keys {
+		jwk, ok := key.(*JWK)
+		if !ok {
+			var err error
+			if jwk, err = NewJWK(key); err != nil {
+				return nil, err
+			}
+		}
+		public := jwk.Public()
+		if public == nil {
+			return nil, fmt.Errorf("symmetric keys can not be published: %w", ErrKeyTypeUnsupported)
+		}
+		set.Keys = append(set.Keys, public)
+	}
+	document, err := json.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(document)
+	}), nil
+}
+
+// rawFromKey converts a key to its JWK members
+func rawFromKey(key interface{}) (*rawJWK, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return &rawJWK{
+			Kty: "RSA",
+			N:   encodeBigInt(k.N),
+			E:   encodeBigInt(big.NewInt(int64(k.E))),
+		}, nil
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("multi-prime rsa keys are not supported: %w", ErrKeyTypeUnsupported)
+		}
+		raw, _ := rawFromKey(&k.PublicKey)
+		p, q := k.Primes[0], k.Primes[1]
+		one := big.NewInt(1)
+		raw.D = encodeBigInt(k.D)
+		raw.P = encodeBigInt(p)
+		raw.Q = encodeBigInt(q)
+		raw.Dp = encodeBigInt(new(big.Int).Mod(k.D, new(big.Int).Sub(p, one)))
+		raw.Dq = encodeBigInt(new(big.Int).Mod(k.D, new(big.Int).Sub(q, one)))
+		raw.Qi = encodeBigInt(new(big.Int).ModInverse(q, p))
+		return raw, nil
+	case *ecdsa.PublicKey:
+		params := k.Curve.Params()
+		switch params.Name {
+		case "P-256", "P-384", "P-521":
+		default:
+			return nil, fmt.Errorf("unsupported ecdsa curve %v: %w", params.Name, ErrKeyTypeUnsupported)
+		}
+		size := (params.BitSize + 7) / 8
+		return &rawJWK{
+			Kty: "EC",
+			Crv: params.Name,
+			X:   encodeFixed(k.X, size),
+			Y:   encodeFixed(k.Y, size),
+		}, nil
+	case *ecdsa.PrivateKey:
+		raw, err := rawFromKey(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		raw.D = encodeFixed(k.D, (k.Curve.Params().BitSize+7)/8)
+		return raw, nil
+	case ed25519.PublicKey:
+		return &rawJWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	case ed25519.PrivateKey:
+		raw, _ := rawFromKey(k.Public())
+		raw.D = base64.RawURLEncoding.EncodeToString(k.Seed())
+		return raw, nil
+	case []byte:
+		return &rawJWK{
+			Kty: "oct",
+			K:   base64.RawURLEncoding.EncodeToString(k),
+		}, nil
+	}
+	return nil, fmt.Errorf("%T: %w", key, ErrKeyTypeUnsupported)
+}
+
+// encodeBigInt encodes an unsigned integer as base64url without leading zeros
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// encodeFixed encodes an unsigned integer as base64url using exactly size bytes
+func encodeFixed(i *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(i.FillBytes(make([]byte, size)))
+}
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWK export", func() {
+
+	It("should compute the RFC 7638 thumbprint", func() {
+		set, err := ParseJWKSet(rfc7517PublicKeys)
+		Expect(err).NotTo(HaveOccurred())
+		thumbprint, err := set.Keys[1].Thumbprint()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(thumbprint).To(Equal("NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"))
+	})
+
+	It("should round trip keys through json", func() {
+		for _, data := range [][]byte{rsaPrivKey, rsaPubKey, ecdsaPrivKey, ecdsaPubKey, ed25519PrivKey, ed25519PubKey} {
+			key, err := ParsePrivateKey(data)
+			if err != nil {
+				key, err = ParsePublicKey(data)
+			}
+			Expect(err).NotTo(HaveOccurred())
+			jwk, err := NewJWK(key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jwk.KeyID).NotTo(BeEmpty())
+			Expect(jwk.Use).To(Equal("sig"))
+
+			bs, err := json.Marshal(jwk)
+			Expect(err).NotTo(HaveOccurred())
+			parsed, err := ParseJWK(bs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.KeyID).To(Equal(jwk.KeyID))
+			Expect(publicKey(parsed.Key)).To(Equal(publicKey(key)))
+		}
+	})
+
+	It("should only export the public key of a public JWK", func() {
+		key, err := ParsePrivateKey(ecdsaPrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		jwk, err := NewJWK(key)
+		Expect(err).NotTo(HaveOccurred())
+		bs, err := json.Marshal(jwk.Public())
+		Expect(err).NotTo(HaveOccurred())
+		members := map[string]interface{}{}
+		Expect(json.Unmarshal(bs, &members)).To(Succeed())
+		Expect(members).NotTo(HaveKey("d"))
+		Expect(members).To(HaveKeyWithValue("crv", "P-521"))
+		Expect(members).To(HaveKeyWithValue("kid", jwk.KeyID))
+	})
+
+	It("should serve a JWKS document usable for validation", func() {
+		rsaPriv, err := ParsePrivateKey(rsaPrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		edPriv, err := ParsePrivateKey(ed25519PrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		rsaJWK, err := NewJWK(rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler, err := JWKSHandler(rsaJWK, edPriv)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(w.Body.String()).NotTo(ContainSubstring(`"d"`))
+
+		set, err := ParseJWKSet(w.Body.Bytes())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(2))
+
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithKeyID(rsaJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, set.KeySet())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should NOT publish symmetric keys", func() {
+		_, err := JWKSHandler([]byte("secret"))
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,200 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWK", func() {
+
+	It("should parse the example public key set of RFC 7517", func() {
+		set, err := ParseJWKSet(rfc7517PublicKeys)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(2))
+
+		ecKey := set.Keys[0]
+		Expect(ecKey.Key).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+		Expect(ecKey.KeyID).To(Equal("1"))
+		Expect(ecKey.Use).To(Equal("enc"))
+
+		rsaKey := set.Keys[1]
+		Expect(rsaKey.Key).To(BeAssignableToTypeOf(&rsa.PublicKey{}))
+		Expect(rsaKey.KeyID).To(Equal("2011-04-29"))
+		Expect(rsaKey.Algorithm).To(Equal("RS256"))
+
+		keys := set.KeySet()
+		Expect(keys).To(HaveLen(1))
+		Expect(keys).To(HaveKey("2011-04-29"))
+	})
+
+	It("should parse Ed25519 keys (RFC 8037) usable for signing and validation", func() {
+		priv, err := ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","kid":"ed","alg":"EdDSA","key_ops":["sign"],
+			"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(priv.Key).To(BeAssignableToTypeOf(ed25519.PrivateKey{}))
+		Expect(priv.KeyOps).To(Equal([]string{"sign"}))
+		pub, err := ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := CreateToken(Claims{"foo": "bar"}, priv.Key)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, pub.Key)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should parse public Ed25519 keys with an alg", func() {
+		pub, err := ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","alg":"EdDSA","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pub.Key).To(BeAssignableToTypeOf(ed25519.PublicKey{}))
+		_, err = ParseJWKSet([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","kid":"ed","alg":"EdDSA","use":"sig","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ParseJWK([]byte(`{"kty":"OKP","crv":"Ed25519","alg":"ES256","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+		Expect(errors.Is(err, ErrInvalidJWK)).To(BeTrue())
+	})
+
+	It("should parse symmetric keys", func() {
+		key, err := ParseJWK([]byte(`{"kty":"oct","k":"c2VjcmV0","alg":"HS256"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.Key).To(Equal([]byte("secret")))
+	})
+
+	It("should parse private keys and their public keys should validate tokens", func() {
+		set, err := ParseJWKSet(rfc7517PrivateKeys)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(2))
+		Expect(set.Keys[0].Key).To(BeAssignableToTypeOf(&ecdsa.PrivateKey{}))
+		Expect(set.Keys[1].Key).To(BeAssignableToTypeOf(&rsa.PrivateKey{}))
+
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, set.Keys[1].Key, WithKeyID("2011-04-29"), WithAlgorithm("RS256"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, set.KeySet())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should only accept the declared alg of a key", func() {
+		set, err := ParseJWKSet(rfc7517PrivateKeys)
+		Expect(err).NotTo(HaveOccurred())
+		keys := set.KeySet()
+		Expect(keys["2011-04-29"]).To(Equal(PinnedKey{Key: publicKey(set.Keys[1].Key), Algorithm: "RS256"}))
+		for _, alg := range []string{"RS384", "RS512", "PS256"} {
+			token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, set.Keys[1].Key, WithKeyID("2011-04-29"), WithAlgorithm(alg))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ValidateTokenWithOptions(token, keys, AllowRSAPSS())
+			Expect(errors.Is(err, ErrAlgorithmNotAllowed)).To(BeTrue())
+		}
+	})
+
+	It("should leave symmetric keys out of key sets", func() {
+		set, err := ParseJWKSet([]byte(`{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(1))
+		Expect(set.KeySet()).To(BeEmpty())
+
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, []byte("secret"), WithKeyID("hmac"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, set.KeySet())
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+	})
+
+	It("should leave keys which may not verify signatures out of key sets", func() {
+		set, err := ParseJWKSet([]byte(`{"keys":[
+			{"kty":"EC","crv":"P-256","kid":"enc","key_ops":["encrypt"],"x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"},
+			{"kty":"EC","crv":"P-256","kid":"sig","key_ops":["verify"],"x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(2))
+		keys := set.KeySet()
+		Expect(keys).To(HaveLen(1))
+		Expect(keys).To(HaveKey("sig"))
+	})
+
+	It("should ignore keys with unknown key types in sets but not single keys", func() {
+		set, err := ParseJWKSet([]byte(`{"keys":[{"kty":"FOO","kid":"x"},{"kty":"oct","k":"c2VjcmV0","kid":"y"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(set.Keys).To(HaveLen(1))
+
+		_, err = ParseJWK([]byte(`{"kty":"FOO"}`))
+		Expect(errors.Is(err, ErrInvalidJWK)).To(BeTrue())
+	})
+
+	It("should reject malformed or mismatched keys", func() {
+		for _, data := range []string{
+			`not json`,
+			`{}`,
+			`{"kty":"oct"}`,
+			`{"kty":"oct","k":"c2VjcmV0="}`,
+			`{"kty":"oct","k":"c2VjcmV0","alg":"RS256"}`,
+			`{"kty":"oct","k":"c2VjcmV0","use":"enc","alg":"HS256"}`,
+			`{"kty":"EC","crv":"P-256","use":"enc","alg":"ES256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"}`,
+			`{"kty":"oct","k":"c2VjcmV0","use":"sig","key_ops":["encrypt"]}`,
+			`{"kty":"oct","k":"c2VjcmV0","key_ops":["sign","sign"]}`,
+			`{"kty":"oct","k":"c2VjcmV0","use":"foo"}`,
+			`{"kty":"OKP","crv":"X25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+			`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcH"}`,
+			`{"kty":"OKP","crv":"Ed25519","d":"AWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+			`{"kty":"EC","crv":"P-256","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`,
+			`{"kty":"EC","crv":"P-384","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"}`,
+			`{"kty":"EC","crv":"P-256","alg":"ES384","x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4","y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM"}`,
+			`{"kty":"RSA","n":"0vx7","e":"AQAB","p":"AQAB"}`,
+			`{"kty":"RSA","n":"0vx7"}`,
+		} {
+			_, err := ParseJWK([]byte(data))
+			Expect(errors.Is(err, ErrInvalidJWK)).To(BeTrue(), data)
+		}
+	})
+
+	It("should reject key sets with malformed keys", func() {
+		_, err := ParseJWKSet([]byte(`{"keys":[{"kty":"oct"}]}`))
+		Expect(errors.Is(err, ErrInvalidJWK)).To(BeTrue())
+		_, err = ParseJWKSet([]byte(`{"foo":[]}`))
+		Expect(errors.Is(err, ErrInvalidJWK)).To(BeTrue())
+	})
+
+})
+
+var (
+	// examples from RFC 7517 appendix A.1 and A.2
+	rfc7517PublicKeys = []byte(`{"keys":
+       [
+         {"kty":"EC",
+          "crv":"P-256",
+          "x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4",
+          "y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM",
+          "use":"enc",
+          "kid":"1"},
+
+         {"kty":"RSA",
+          "n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+          "e":"AQAB",
+          "alg":"RS256",
+          "kid":"2011-04-29"}
+       ]
+     }`)
+	rfc7517PrivateKeys = []byte(`{"keys":
+       [
+         {"kty":"EC",
+          "crv":"P-256",
+          "x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4",
+          "y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM",
+          "d":"870MB6gfuTJ4HtUnUvYMyJpr5eUZNP4Bk43bVdj3eAE",
+          "use":"enc",
+          "kid":"1"},
+
+         {"kty":"RSA",
+          "n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+          "e":"AQAB",
+          "d":"X4cTteJY_gn4FYPsXB8rdXix5vwsg1FLN5E3EaG6RJoVH-HLLKD9M7dx5oo7GURknchnrRweUkC7hT5fJLM0WbFAKNLWY2vv7B6NqXSzUvxT0_YSfqijwp3RTzlBaCxWp4doFk5N2o8Gy_nHNKroADIkJ46pRUohsXywbReAdYaMwFs9tv8d_cPVY3i07a3t8MN6TNwm0dSawm9v47UiCl3Sk5ZiG7xojPLu4sbg1U2jx4IBTNBznbJSzFHK66jT8bgkuqsk0GjskDJk19Z4qwjwbsnn4j2WBii3RL-Us2lGVkY8fkFzme1z0HbIkfz0Y6mqnOYtqc0X4jfcKoAC8Q",
+          "p":"83i-7IvMGXoMXCskv73TKr8637FiO7Z27zv8oj6pbWUQyLPQBQxtPVnwD20R-60eTDmD2ujnMt5PoqMrm8RfmNhVWDtjjMmCMjOpSXicFHj7XOuVIYQyqVWlWEh6dN36GVZYk93N8Bc9vY41xy8B9RzzOGVQzXvNEvn7O0nVbfs",
+          "q":"3dfOR9cuYq-0S-mkFLzgItgMEfFzB2q3hWehMuG0oCuqnb3vobLyumqjVZQO1dIrdwgTnCdpYzBcOfW5r370AFXjiWft_NGEiovonizhKpo9VVS78TzFgxkIdrecRezsZ-1kYd_s1qDbxtkDEgfAITAG9LUnADun4vIcb6yelxk",
+          "dp":"G4sPXkc6Ya9y8oJW9_ILj4xuppu0lzi_H7VTkS8xj5SdX3coE0oimYwxIi2emTAue0UOa5dpgFGyBJ4c8tQ2VF402XRugKDTP8akYhFo5tAA77Qe_NmtuYZc3C3m3I24G2GvR5sSDxUyAN2zq8Lfn9EUms6rY3Ob8YeiKkTiBj0",
+          "dq":"s9lAH9fggBsoFR8Oac2R_E2gw282rT2kGOAhvIllETE1efrA6huUUvMfBcMpn8lqeW6vzznYY5SSQF7pMdC_agI3nG8Ibp1BUb0JUiraRNqUfLhcQb_d9GF4Dh7e74WbRsobRonujTYN1xCaP6TO61jvWrX-L18txXw494Q_cgk",
+          "qi":"GyM_p6JrXySiz1toFgKbWV-JdI3jQ4ypu9rbMWx3rQJBfmt0FoYzgUIZEVFEcOqwemRN81zoDAaa-Bk0KWNGDjJHZDdDmFhW3AN7lI-puxk_mYHGTKTFu9ZM1V1zjM-d7x7EDiXbZ7bHCw4aGl_tKm3oE6WEiK7lnDIpbiBtQkk",
+          "alg":"RS256",
+          "kid":"2011-04-29"}
+       ]
+     }`)
+)
@@ -2,10 +2,7 @@ package jwt
 
 import (
 	"bytes"
-	"crypto/ecdsa"
-	"crypto/rsa"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -23,91 +20,87 @@ const (
 // Claims is a map of string->something containing the meta infos associated with a token
 type Claims map[string]interface{}
 
-// CreateToken takes some claims and a private key (either rsa or ec) and returns a signed json web token
+// CreateToken takes some claims and a private key (either rsa, ec, ed25519 or hmac) and returns a signed json web token.
+// Any other crypto.Signer (e.g. a KMS or HSM held key) can be used as well, the algorithm is derived from its public key.
 func CreateToken(claims Claims, key interface{}) (string, error) {
-	switch k := key.(type) {
-	case *rsa.PrivateKey:
-		{
-			token := jwt.NewWithClaims(jwt.SigningMethodRS512, jwt.MapClaims(claims))
-			return token.SignedString(k)
-		}
-	case *ecdsa.PrivateKey:
-		{
-			token := jwt.NewWithClaims(jwt.SigningMethodES512, jwt.MapClaims(claims))
-			return token.SignedString(k)
-		}
-	case []byte:
-		{
-			token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims(claims))
-			return token.SignedString(k)
-		}
-	}
-	return "", errors.New("invalid private key")
+	return CreateTokenWithOptions(claims, key)
 }
 
-// ValidateToken checks the signature of the token with a given public key and returns the associated claims
-func ValidateToken(tokenString string, key interface{}) (Claims, error) {
-	var (
-		token *jwt.Token
-		err   error
-	)
-	switch k := key.(type) {
-	case *rsa.PublicKey:
-		{
-			token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-				}
-				return k, nil
-			})
-		}
-	case *ecdsa.PublicKey:
-		{
-			token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-				}
-				return k, nil
-			})
-		}
-	case []byte:
-		{
-			token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-				}
-				return k, nil
-			})
-		}
+// CreateTokenWithOptions works like CreateToken but allows to customize the token, e.g. the signing algorithm
+func CreateTokenWithOptions(claims Claims, key interface{}, opts ...TokenOption) (string, error) {
+	options := &tokenOptions{}
+	for _, opt := range opts {
+		opt(options)
 	}
+	method, err := signingMethodForKey(key, options.algorithm)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if token != nil {
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			return Claims(claims), nil
+	token := jwt.NewWithClaims(method, jwt.MapClaims(claims))
+	for name, value := range options.header {
+		if name == "alg" {
+			return "", fmt.Errorf("%w: the alg header is set by the signing algorithm", ErrInvalidHeader)
 		}
+		token.Header[name] = value
+	}
+	if options.keyID != "" {
+		token.Header["kid"] = options.keyID
 	}
-	return nil, errors.New("invalid token")
+	return token.SignedString(key)
+}
+
+// ValidateToken checks the signature of the token with a given public key and returns the associated claims
+func ValidateToken(tokenString string, key interface{}) (Claims, error) {
+	return NewValidator(key).Validate(tokenString)
+}
+
+// ValidateTokenWithOptions works like ValidateToken but allows to customize the validation rules
+func ValidateTokenWithOptions(tokenString string, key interface{}, opts ...ValidationOption) (Claims, error) {
+	return NewValidator(key, opts...).Validate(tokenString)
 }
 
 // GetUnvalidatedClaims extracts the token claims without validating the token
 func GetUnvalidatedClaims(tokenString string) (claims Claims, err error) {
+	return getUnvalidatedClaims(tokenString, false)
+}
 
+// GetUnvalidatedClaimsWithJSONNumber works like GetUnvalidatedClaims but decodes numbers as json.Number
+// instead of float64, so large integers keep their precision
+func GetUnvalidatedClaimsWithJSONNumber(tokenString string) (claims Claims, err error) {
+	return getUnvalidatedClaims(tokenString, true)
+}
+
+func getUnvalidatedClaims(tokenString string, useNumber bool) (claims Claims, err error) {
+	claimBytes, err := claimsSegment(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewBuffer(claimBytes))
+	if useNumber {
+		dec.UseNumber()
+	}
+	if err = dec.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+	return claims, nil
+}
+
+// claimsSegment returns the decoded claims segment of the token
+func claimsSegment(tokenString string) ([]byte, error) {
 	parts := strings.Split(tokenString, ".")
 	if len(parts) != 3 {
-		return nil, errors.New("token contains an invalid number of segments")
+		return nil, fmt.Errorf("%w: token contains an invalid number of segments", ErrTokenMalformed)
 	}
 
 	claimBytes, err := jwt.DecodeSegment(parts[1])
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
 	}
-
-	return claims, json.NewDecoder(bytes.NewBuffer(claimBytes)).Decode(&claims)
+	return claimBytes, nil
 }
 
-// LoadPublicKey loads a PEM encoded public key (either rsa or ec)
+// LoadPublicKey loads a PEM encoded public key (either rsa, ec or ed25519)
 func LoadPublicKey(keyFile string) (interface{}, error) {
 	bs, err := ioutil.ReadFile(keyFile)
 	if err != nil {
@@ -116,20 +109,13 @@ func LoadPublicKey(keyFile string) (interface{}, error) {
 	return ParsePublicKey(bs)
 }
 
-// ParsePublicKey parses a pem encoded public key (rsa or ecdsa based)
+// ParsePublicKey parses a pem encoded public key (rsa, ecdsa or ed25519 based). Supported are PKIX and
+// PKCS#1 public keys and X.509 certificates, the first public key found is returned.
 func ParsePublicKey(data []byte) (interface{}, error) {
-	rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
-	if err != nil {
-		ecKey, err := jwt.ParseECPublicKeyFromPEM(data)
-		if err != nil {
-			return nil, errors.New("unknown public key type")
-		}
-		return ecKey, nil
-	}
-	return rsaKey, nil
+	return parsePEMKey(data, false, nil)
 }
 
-// LoadPrivateKey loads a PEM encoded private key (either rsa or ec)
+// LoadPrivateKey loads a PEM encoded private key (either rsa, ec or ed25519)
 func LoadPrivateKey(keyFile string) (interface{}, error) {
 	bs, err := ioutil.ReadFile(keyFile)
 	if err != nil {
@@ -138,17 +124,10 @@ func LoadPrivateKey(keyFile string) (interface{}, error) {
 	return ParsePrivateKey(bs)
 }
 
-// ParsePrivateKey parses a pem encoded private key (rsa or ecdsa based)
+// ParsePrivateKey parses a pem encoded private key (rsa, ecdsa or ed25519 based). Supported are PKCS#1,
+// PKCS#8 and SEC1 private keys, the first private key found is returned.
 func ParsePrivateKey(data []byte) (interface{}, error) {
-	rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
-	if err != nil {
-		ecKey, err := jwt.ParseECPrivateKeyFromPEM(data)
-		if err != nil {
-			return nil, errors.New("unknown public key type")
-		}
-		return ecKey, nil
-	}
-	return rsaKey, nil
+	return parsePEMKey(data, true, nil)
 }
 
 // GetTokenFromRequest takes the first Authorization header or `token` GET pararm , then
@@ -166,7 +145,7 @@ func GetTokenFromRequest(r *http.Request, header string) (prefix string, token s
 
 	if len(tokenList) < 1 {
 		prefix = ""
-		return prefix, token, errors.New("no valid authorization header")
+		return prefix, token, ErrTokenMissing
 	}
 
 	tokenParts := strings.Fields(tokenList[0])
@@ -177,7 +156,7 @@ func GetTokenFromRequest(r *http.Request, header string) (prefix string, token s
 		prefix = tokenParts[0]
 		token = tokenParts[1]
 	default:
-		return prefix, token, errors.New("invalid token: unexpected number of parts")
+		return prefix, token, fmt.Errorf("%w: unexpected number of parts", ErrTokenMalformed)
 	}
 
 	return prefix, token, nil
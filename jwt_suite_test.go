@@ -7,6 +7,20 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// rsaPriv and rsaPub are the parsed rsaPrivKey and rsaPubKey, shared by all specs
+var (
+	rsaPriv interface{}
+	rsaPub  interface{}
+)
+
+var _ = BeforeSuite(func() {
+	var err error
+	rsaPriv, err = ParsePrivateKey(rsaPrivKey)
+	Expect(err).NotTo(HaveOccurred())
+	rsaPub, err = ParsePublicKey(rsaPubKey)
+	Expect(err).NotTo(HaveOccurred())
+})
+
 func TestJwt(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Jwt Suite")
@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"fmt"
+)
+
+// KeyProvider returns the candidate keys to verify a token with. It gets the header and the
+// (not yet verified) claims of the token, so it can select keys e.g. by "kid" or "iss".
+// A KeyProvider can be used everywhere a public key is accepted.
+type KeyProvider interface {
+	Keys(header map[string]interface{}, claims Claims) ([]interface{}, error)
+}
+
+// StaticKey returns a KeyProvider which always returns the given key
+func StaticKey(key interface{}) KeyProvider {
+	return staticKey{key}
+}
+
+type staticKey struct {
+	key interface{}
+}
+
+func (k staticKey) Keys(map[string]interface{}, Claims) ([]interface{}, error) {
+	return []interface{}{k.key}, nil
+}
+
+// KeySet is a set of public keys indexed by their key id. It can be passed to ValidateToken
+// instead of a single key, the key is then selected by the "kid" header of the token.
+type KeySet map[string]interface{}
+
+// Lookup returns the key for the given key id
+func (s KeySet) Lookup(kid string) (interface{}, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("%w: token has no kid header", ErrUnknownKeyID)
+	}
+	key, ok := s[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	return key, nil
+}
+
+// Keys returns the key matching the "kid" header
+func (s KeySet) Keys(header map[string]interface{}, _ Claims) ([]interface{}, error) {
+	return keysByKeyID(s, header)
+}
+
+// PinnedKey restricts a public key to a single signing algorithm, e.g. the "alg" of a JWK. It can be
+// used in a KeySet or returned by a KeyProvider, tokens with another "alg" header are rejected with
+// ErrAlgorithmNotAllowed.
+type PinnedKey struct {
+	Key       interface{}
+	Algorithm string
+}
+
+// IssuerKeys selects the KeyProvider by the "iss" claim of the token, so tokens of multiple
+// issuers can be validated with their respective keys.
+type IssuerKeys map[string]KeyProvider
+
+// Keys returns the keys of the provider registered for the issuer of the token
+func (k IssuerKeys) Keys(header map[string]interface{}, claims Claims) ([]interface{}, error) {
+	iss, _ := claims["iss"].(string)
+	provider, ok := k[iss]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIssuer, iss)
+	}
+	return provider.Keys(header, claims)
+}
+
+// keyLookup is implemented by key sets which select the key by key id
+type keyLookup interface {
+	Lookup(kid string) (interface{}, error)
+}
+
+// keysByKeyID looks up the key for the "kid" header
+func keysByKeyID(keys keyLookup, header map[string]interface{}) ([]interface{}, error) {
+	kid, _ := header["kid"].(string)
+	key, err := keys.Lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{key}, nil
+}
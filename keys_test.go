@@ -0,0 +1,190 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeySet", func() {
+
+	var (
+		oldKey *ecdsa.PrivateKey
+		newKey *ecdsa.PrivateKey
+		keys   KeySet
+	)
+
+	BeforeEach(func() {
+		var err error
+		oldKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		newKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		keys = KeySet{
+			"old": &oldKey.PublicKey,
+			"new": &newKey.PublicKey,
+		}
+	})
+
+	It("should set the kid header when creating a token", func() {
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, newKey, WithKeyID("new"))
+		Expect(err).NotTo(HaveOccurred())
+		header, err := getTokenHeader(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header["kid"]).To(Equal("new"))
+	})
+
+	It("should select the key by kid when validating", func() {
+		claims := Claims{"foo": "bar"}
+		for kid, key := range map[string]*ecdsa.PrivateKey{"old": oldKey, "new": newKey} {
+			token, err := CreateTokenWithOptions(claims, key, WithKeyID(kid))
+			Expect(err).NotTo(HaveOccurred())
+			reClaims, err := ValidateToken(token, keys)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reClaims).To(Equal(claims))
+		}
+	})
+
+	It("should NOT validate a token signed with a different key than its kid claims", func() {
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, oldKey, WithKeyID("new"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(errors.Is(err, ErrSignatureInvalid)).To(BeTrue())
+	})
+
+	It("should fail with ErrUnknownKeyID for unknown or missing kids", func() {
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, newKey, WithKeyID("unknown"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("unknown"))
+
+		token, err = CreateToken(Claims{"foo": "bar"}, newKey)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+	})
+
+})
+
+// keyProviderFunc adapts a function to a KeyProvider
+type keyProviderFunc func(header map[string]interface{}, claims Claims) ([]interface{}, error)
+
+func (f keyProviderFunc) Keys(header map[string]interface{}, claims Claims) ([]interface{}, error) {
+	return f(header, claims)
+}
+
+var _ = Describe("KeyProvider", func() {
+
+	var (
+		ecPriv interface{}
+		ecPub  interface{}
+	)
+
+	BeforeEach(func() {
+		var err error
+		ecPriv, err = ParsePrivateKey(ecdsaPrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		ecPub, err = ParsePublicKey(ecdsaPubKey)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate with a static key provider", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, StaticKey(rsaPub))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, StaticKey(ecPub))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should try all candidate keys of a provider", func() {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		var gotHeader map[string]interface{}
+		var gotClaims Claims
+		provider := keyProviderFunc(func(header map[string]interface{}, claims Claims) ([]interface{}, error) {
+			gotHeader, gotClaims = header, claims
+			return []interface{}{rsaPub, &otherKey.PublicKey, ecPub}, nil
+		})
+		token, err := CreateToken(Claims{"foo": "bar"}, ecPriv)
+		Expect(err).NotTo(HaveOccurred())
+		reClaims, err := ValidateToken(token, provider)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(Equal(Claims{"foo": "bar"}))
+		Expect(gotHeader).To(HaveKeyWithValue("alg", "ES512"))
+		Expect(gotClaims).To(HaveKeyWithValue("foo", "bar"))
+
+		token, err = CreateToken(Claims{"foo": "bar"}, otherKey)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keyProviderFunc(func(map[string]interface{}, Claims) ([]interface{}, error) {
+			return []interface{}{rsaPub, ecPub}, nil
+		}))
+		Expect(errors.Is(err, ErrSignatureInvalid)).To(BeTrue())
+	})
+
+	It("should fail if the provider returns no keys or an error", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keyProviderFunc(func(map[string]interface{}, Claims) ([]interface{}, error) {
+			return nil, nil
+		}))
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+		_, err = ValidateToken(token, keyProviderFunc(func(map[string]interface{}, Claims) ([]interface{}, error) {
+			return nil, errors.New("boom")
+		}))
+		Expect(errors.Is(err, ErrTokenUnverifiable)).To(BeTrue())
+	})
+
+	It("should select the keys by issuer", func() {
+		issuers := IssuerKeys{
+			"rsa-idp": StaticKey(rsaPub),
+			"ec-idp":  KeySet{"ec": ecPub},
+		}
+		token, err := CreateToken(Claims{"iss": "rsa-idp"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, issuers)
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err = CreateTokenWithOptions(Claims{"iss": "ec-idp"}, ecPriv, WithKeyID("ec"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, issuers)
+		Expect(err).NotTo(HaveOccurred())
+
+		// a token claiming to be from the rsa idp but signed with the ec key
+		token, err = CreateTokenWithOptions(Claims{"iss": "rsa-idp"}, ecPriv, WithKeyID("ec"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, issuers)
+		Expect(err).To(HaveOccurred())
+
+		token, err = CreateToken(Claims{"iss": "unknown"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, issuers)
+		Expect(errors.Is(err, ErrInvalidIssuer)).To(BeTrue())
+	})
+
+	It("should load the key from a file", func() {
+		ioutil.WriteFile("/tmp/file-key-pub.pem", rsaPubKey, 0644)
+		provider, err := NewFileKey("/tmp/file-key-pub.pem")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(provider.Key()).To(Equal(rsaPub))
+
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		r, _ := http.NewRequest("GET", "http://foobar.com", nil)
+		r.Header.Add("Authorization", "Bearer "+token)
+		_, reClaims, err := GetClaimsFromRequestWithValidation(r, "", provider)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(Equal(Claims{"foo": "bar"}))
+
+		_, err = NewFileKey("/tmp/not-here")
+		Expect(err).To(HaveOccurred())
+	})
+
+})
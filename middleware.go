@@ -28,6 +28,24 @@ func ClaimsToContextMiddleware(handler http.Handler, header string, idpKey inter
 	})
 }
 
+// ClaimsToContextMiddlewareWithValidator works like ClaimsToContextMiddleware but validates the token with the given validator
+func ClaimsToContextMiddlewareWithValidator(handler http.Handler, header string, validator *Validator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var claims Claims
+		_, token, err := GetTokenFromRequest(r, header)
+		if err == nil {
+			claims, err = validator.Validate(token)
+		}
+		if err != nil {
+			http.Error(w, "not authorized: failed to validate token: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ctx := ClaimsToContext(r.Context(), claims)
+		r = r.WithContext(ctx)
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // RequireClaim checks if the requests claims contain a specific value for a specific key
 func RequireClaim(handler http.Handler, claimKey, expectedClaimValue string) http.Handler {
 	log := logrus.
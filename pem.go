@@ -0,0 +1,162 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ErrNoPEMData is returned when the data to parse contains no PEM encoded key
+var ErrNoPEMData = errors.New("no pem encoded key found")
+
+// KeyFormatError is returned when a PEM block can not be parsed in the format indicated by its type
+type KeyFormatError struct {
+	// Format is the key format which failed, e.g. "PKCS#8 private key"
+	Format string
+	Err    error
+}
+
+// Error describes which format failed and why
+func (e *KeyFormatError) Error() string {
+	return fmt.Sprintf("failed to parse %v: %v", e.Format, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *KeyFormatError) Unwrap() error {
+	return e.Err
+}
+
+// pemFormat describes how to parse a PEM block type
+type pemFormat struct {
+	name    string
+	private bool
+	parse   func([]byte) (interface{}, error)
+}
+
+var pemFormats = map[string]pemFormat{
+	"RSA PRIVATE KEY": {"PKCS#1 private key", true, func(der []byte) (interface{}, error) {
+		return x509.ParsePKCS1PrivateKey(der)
+	}},
+	"EC PRIVATE KEY": {"SEC1 private key", true, func(der []byte) (interface{}, error) {
+		return x509.ParseECPrivateKey(der)
+	}},
+	"PRIVATE KEY": {"PKCS#8 private key", true, x509.ParsePKCS8PrivateKey},
+	"RSA PUBLIC KEY": {"PKCS#1 public key", false, func(der []byte) (interface{}, error) {
+		return x509.ParsePKCS1PublicKey(der)
+	}},
+	"PUBLIC KEY": {"PKIX public key", false, x509.ParsePKIXPublicKey},
+	"CERTIFICATE": {"X.509 certificate", false, func(der []byte) (interface{}, error) {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}},
+}
+
+// ParseKeys parses all keys of a PEM bundle. Supported are PKCS#1, PKCS#8 and SEC1 private keys,
+// PKCS#1 and PKIX public keys and the public keys of X.509 certificates (rsa, ecdsa and ed25519).
+func ParseKeys(data []byte) ([]interface{}, error) {
+	var keys []interface{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == "EC PARAMETERS" {
+			continue
+		}
+		key, err := parsePEMBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoPEMData
+	}
+	return keys, nil
+}
+
+// parsePEMKey returns the first private or public key of the PEM data. Encrypted private keys
+// are decrypted with the passphrase.
+func parsePEMKey(data []byte, private bool, passphrase PassphraseFunc) (interface{}, error) {
+	kind := "public"
+	if private {
+		kind = "private"
+	}
+	var skipped []string
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type == "EC PARAMETERS" {
+			continue
+		}
+		if encryptedPEMBlock(block) {
+			if !private {
+				skipped = append(skipped, block.Type)
+				continue
+			}
+			plain, err := decryptPEMBlock(block, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			key, err := parsePEMBlock(plain)
+			var formatErr *KeyFormatError
+			if errors.As(err, &formatErr) {
+				// a wrong passphrase can pass the padding check, the decrypted data is garbage then
+				return nil, fmt.Errorf("%w: %v", ErrIncorrectPassphrase, err)
+			}
+			return key, err
+		}
+		if format, ok := pemFormats[block.Type]; ok && format.private != private {
+			skipped = append(skipped, block.Type)
+			continue
+		}
+		return parsePEMBlock(block)
+	}
+	if len(skipped) > 0 {
+		return nil, fmt.Errorf("%w: expected a %v key, found %v", ErrNoPEMData, kind, skipped)
+	}
+	return nil, ErrNoPEMData
+}
+
+// parsePEMBlock parses a PEM block according to its type. If that fails the other formats of the
+// same kind are tried as well, as some tools do not label their PEM blocks correctly.
+func parsePEMBlock(block *pem.Block) (interface{}, error) {
+	if encryptedPEMBlock(block) {
+		return nil, fmt.Errorf("%w: use ParsePrivateKeyWithPassphrase", ErrKeyEncrypted)
+	}
+	format, ok := pemFormats[block.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown pem block type %q", ErrKeyTypeUnsupported, block.Type)
+	}
+	key, err := format.parse(block.Bytes)
+	if err != nil {
+		for _, other := range pemFormats {
+			if other.private != format.private || other.name == format.name {
+				continue
+			}
+			if k, e := other.parse(block.Bytes); e == nil {
+				key, err = k, nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, &KeyFormatError{Format: format.name, Err: err}
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, &KeyFormatError{Format: format.name, Err: fmt.Errorf("%w: %T", ErrKeyTypeUnsupported, key)}
+}
@@ -0,0 +1,276 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrKeyEncrypted is returned when an encrypted private key is parsed without a passphrase
+	ErrKeyEncrypted = errors.New("private key is encrypted")
+	// ErrIncorrectPassphrase is returned when an encrypted private key can not be decrypted with the passphrase
+	ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+)
+
+// PassphraseFunc returns the passphrase to decrypt a private key. It is only called for encrypted keys.
+type PassphraseFunc func() ([]byte, error)
+
+// Passphrase returns a PassphraseFunc for a fixed passphrase
+func Passphrase(passphrase string) PassphraseFunc {
+	return func() ([]byte, error) {
+		return []byte(passphrase), nil
+	}
+}
+
+// PassphraseFromEnv returns a PassphraseFunc which reads the passphrase from the given environment variable
+func PassphraseFromEnv(name string) PassphraseFunc {
+	return func() ([]byte, error) {
+		passphrase, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("passphrase variable %v is not set", name)
+		}
+		return []byte(passphrase), nil
+	}
+}
+
+// LoadPrivateKeyWithPassphrase loads a PEM encoded private key which may be encrypted
+func LoadPrivateKeyWithPassphrase(keyFile string, passphrase PassphraseFunc) (interface{}, error) {
+	bs, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKeyWithPassphrase(bs, passphrase)
+}
+
+// ParsePrivateKeyWithPassphrase parses a pem encoded private key like ParsePrivateKey, encrypted keys are
+// decrypted with the passphrase. Supported are encrypted PKCS#8 keys (PBES2 with PBKDF2 or scrypt and
+// AES-CBC or 3DES-CBC) and legacy encrypted PEM blocks with a DEK-Info header.
+func ParsePrivateKeyWithPassphrase(data []byte, passphrase PassphraseFunc) (interface{}, error) {
+	return parsePEMKey(data, true, passphrase)
+}
+
+// encryptedPEMBlock returns if the block contains an encrypted private key
+func encryptedPEMBlock(block *pem.Block) bool {
+	_, legacy := block.Headers["DEK-Info"]
+	return legacy || block.Type == "ENCRYPTED PRIVATE KEY"
+}
+
+// decryptPEMBlock decrypts an encrypted private key block and returns the plain block
+func decryptPEMBlock(block *pem.Block, passphrase PassphraseFunc) (*pem.Block, error) {
+	if passphrase == nil {
+		return nil, fmt.Errorf("%w: use ParsePrivateKeyWithPassphrase", ErrKeyEncrypted)
+	}
+	password, err := passphrase()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passphrase: %w", err)
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		der, err := decryptPKCS8(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+	}
+	der, err := x509.DecryptPEMBlock(block, password)
+	if err == x509.IncorrectPasswordError {
+		return nil, ErrIncorrectPassphrase
+	}
+	if err != nil {
+		return nil, &KeyFormatError{Format: "encrypted " + block.Type, Err: err}
+	}
+	return &pem.Block{Type: block.Type, Bytes: der}, nil
+}
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo structure of RFC 5208
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params are the PBES2-params of RFC 8018
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the PBKDF2-params of RFC 8018
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// scryptParams are the scrypt-params of RFC 7914
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts an EncryptedPrivateKeyInfo and returns the PKCS#8 encoded private key
+func decryptPKCS8(der []byte, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if err := unmarshalDER(der, &info); err != nil {
+		return nil, pkcs8FormatError(err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("%w: encryption algorithm %v, only PBES2 is supported", ErrKeyTypeUnsupported, info.Algorithm.Algorithm)
+	}
+	var params pbes2Params
+	if err := unmarshalDER(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, pkcs8FormatError(err)
+	}
+
+	newCipher, keyLen, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if err := unmarshalDER(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, pkcs8FormatError(err)
+	}
+	key, err := pbes2Key(params.KeyDerivationFunc, password, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, pkcs8FormatError(err)
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, pkcs8FormatError(errors.New("invalid iv length"))
+	}
+	data := info.EncryptedData
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, pkcs8FormatError(errors.New("invalid encrypted data length"))
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// a wrong passphrase results in random padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, ErrIncorrectPassphrase
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// pbes2Cipher returns the block cipher and its key length for a PBES2 encryption scheme
+func pbes2Cipher(oid asn1.ObjectIdentifier) (func([]byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	}
+	return nil, 0, fmt.Errorf("%w: encryption scheme %v", ErrKeyTypeUnsupported, oid)
+}
+
+// pbes2Key derives the encryption key with PBKDF2 or scrypt
+func pbes2Key(kdf pkix.AlgorithmIdentifier, password []byte, keyLen int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var params pbkdf2Params
+		if err := unmarshalDER(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, pkcs8FormatError(err)
+		}
+		if params.KeyLength != 0 && params.KeyLength != keyLen {
+			return nil, pkcs8FormatError(errors.New("key length does not match the encryption scheme"))
+		}
+		if params.IterationCount < 1 {
+			return nil, pkcs8FormatError(errors.New("invalid iteration count"))
+		}
+		h, err := pbkdf2Hash(params.PRF.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		return pbkdf2.Key(password, params.Salt, params.IterationCount, keyLen, h), nil
+	case kdf.Algorithm.Equal(oidScrypt):
+		var params scryptParams
+		if err := unmarshalDER(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, pkcs8FormatError(err)
+		}
+		if params.KeyLength != 0 && params.KeyLength != keyLen {
+			return nil, pkcs8FormatError(errors.New("key length does not match the encryption scheme"))
+		}
+		key, err := scrypt.Key(password, params.Salt, params.CostParameter, params.BlockSize, params.ParallelizationParameter, keyLen)
+		if err != nil {
+			return nil, pkcs8FormatError(err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: key derivation function %v", ErrKeyTypeUnsupported, kdf.Algorithm)
+}
+
+// pbkdf2Hash returns the hash function of a PBKDF2 pseudorandom function, the default is HMAC-SHA1
+func pbkdf2Hash(prf asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(prf) == 0 || prf.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case prf.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case prf.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case prf.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case prf.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: pbkdf2 function %v", ErrKeyTypeUnsupported, prf)
+}
+
+func pkcs8FormatError(err error) error {
+	return &KeyFormatError{Format: "encrypted PKCS#8 private key", Err: err}
+}
+
+// unmarshalDER unmarshals a complete DER structure
+func unmarshalDER(der []byte, v interface{}) error {
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PEM parsing", func() {
+
+	var (
+		rsaKey *rsa.PrivateKey
+		ecKey  *ecdsa.PrivateKey
+	)
+
+	encode := func(typ string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should parse PKCS#8 private keys", func() {
+		der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+		Expect(err).NotTo(HaveOccurred())
+		key, err := ParsePrivateKey(encode("PRIVATE KEY", der))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*rsa.PrivateKey).Equal(rsaKey)).To(BeTrue())
+
+		der, err = x509.MarshalPKCS8PrivateKey(ecKey)
+		Expect(err).NotTo(HaveOccurred())
+		key, err = ParsePrivateKey(encode("PRIVATE KEY", der))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*ecdsa.PrivateKey).Equal(ecKey)).To(BeTrue())
+	})
+
+	It("should parse PKIX and PKCS#1 public keys", func() {
+		der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		key, err := ParsePublicKey(encode("PUBLIC KEY", der))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*ecdsa.PublicKey).Equal(&ecKey.PublicKey)).To(BeTrue())
+
+		key, err = ParsePublicKey(encode("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*rsa.PublicKey).Equal(&rsaKey.PublicKey)).To(BeTrue())
+	})
+
+	It("should skip EC PARAMETERS blocks as written by openssl", func() {
+		der, err := x509.MarshalECPrivateKey(ecKey)
+		Expect(err).NotTo(HaveOccurred())
+		data := append(encode("EC PARAMETERS", []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}),
+			encode("EC PRIVATE KEY", der)...)
+		key, err := ParsePrivateKey(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*ecdsa.PrivateKey).Equal(ecKey)).To(BeTrue())
+	})
+
+	It("should parse mislabeled blocks", func() {
+		der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+		Expect(err).NotTo(HaveOccurred())
+		key, err := ParsePrivateKey(encode("EC PRIVATE KEY", der))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key.(*ecdsa.PrivateKey).Equal(ecKey)).To(BeTrue())
+	})
+
+	It("should return the first key of the requested kind", func() {
+		der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		data := append(encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), encode("PUBLIC KEY", der)...)
+		key, err := ParsePublicKey(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+		key, err = ParsePrivateKey(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(BeAssignableToTypeOf(&rsa.PrivateKey{}))
+	})
+
+	It("should parse all keys of a bundle", func() {
+		var bundle []byte
+		for _, data := range [][]byte{rsaPubKey, ecdsaPubKey, ed25519PrivKey} {
+			bundle = append(bundle, data...)
+		}
+		keys, err := ParseKeys(bundle)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(HaveLen(3))
+		Expect(keys[0]).To(BeAssignableToTypeOf(&rsa.PublicKey{}))
+		Expect(keys[1]).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+	})
+
+	It("should name the failing format", func() {
+		_, err := ParsePrivateKey(encode("PRIVATE KEY", []byte("garbage")))
+		var formatErr *KeyFormatError
+		Expect(errors.As(err, &formatErr)).To(BeTrue())
+		Expect(formatErr.Format).To(Equal("PKCS#8 private key"))
+
+		_, err = ParsePublicKey(encode("CERTIFICATE", []byte("garbage")))
+		Expect(err).To(MatchError(ContainSubstring("X.509 certificate")))
+	})
+
+	It("should not return a private key as public key", func() {
+		_, err := ParsePublicKey(rsaPrivKey)
+		Expect(errors.Is(err, ErrNoPEMData)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("expected a public key")))
+	})
+
+	It("should reject unknown and encrypted blocks", func() {
+		_, err := ParsePrivateKey(encode("DSA PRIVATE KEY", []byte("garbage")))
+		Expect(errors.Is(err, ErrKeyTypeUnsupported)).To(BeTrue())
+		_, err = ParsePrivateKey(encode("ENCRYPTED PRIVATE KEY", []byte("garbage")))
+		Expect(errors.Is(err, ErrKeyEncrypted)).To(BeTrue())
+		_, err = ParsePrivateKey([]byte("not pem at all"))
+		Expect(err).To(Equal(ErrNoPEMData))
+	})
+})
@@ -0,0 +1,238 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJWKSetSize limits the size of a downloaded JWKS document
+const maxJWKSetSize = 1 << 20
+
+// RemoteKeySet is a set of public keys fetched from a JWKS url. The keys are cached as long
+// as the Cache-Control header of the response allows and refreshed in the background. Tokens
+// with an unknown kid trigger a (rate limited) refresh, so rotated keys are picked up quickly.
+// A RemoteKeySet can be passed to ValidateToken and the middlewares instead of a single key.
+type RemoteKeySet struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	onError            func(error)
+
+	mu        sync.RWMutex
+	keys      KeySet
+	expires   time.Time
+	lastFetch time.Time
+	fetchErr  error
+
+	fetchMu   sync.Mutex
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// RemoteKeySetOption configures a RemoteKeySet
+type RemoteKeySetOption func(*RemoteKeySet)
+
+// WithHTTPClient sets the http client used to fetch the JWKS document
+func WithHTTPClient(client *http.Client) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.client = client
+	}
+}
+
+// WithRefreshInterval sets how long keys are cached if the response has no Cache-Control max-age (default 1h)
+func WithRefreshInterval(interval time.Duration) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.refreshInterval = interval
+	}
+}
+
+// WithMinRefreshInterval sets the minimal time between two fetches (default 1m). It limits how
+// often tokens with unknown kids can trigger a refresh and overrides shorter cache lifetimes.
+func WithMinRefreshInterval(interval time.Duration) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.minRefreshInterval = interval
+	}
+}
+
+// WithRefreshErrorHandler sets a function which is called with the errors of background refreshes
+func WithRefreshErrorHandler(onError func(error)) RemoteKeySetOption {
+	return func(s *RemoteKeySet) {
+		s.onError = onError
+	}
+}
+
+// NewRemoteKeySet creates a RemoteKeySet for the given JWKS url and starts refreshing it in the
+// background. Call Close to stop the background refresh.
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) *RemoteKeySet {
+	s := &RemoteKeySet{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    time.Hour,
+		minRefreshInterval: time.Minute,
+		onError:            func(error) {},
+		stop:               make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.refreshLoop()
+	return s
+}
+
+// Close stops the background refresh
+func (s *RemoteKeySet) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Lookup returns the key for the given key id. The keys are fetched if they are not cached yet
+// or if the key id is unknown and the last fetch is older than the minimal refresh interval.
+func (s *RemoteKeySet) Lookup(kid string) (interface{}, error) {
+	s.mu.RLock()
+	keys, expired := s.keys, s.lastFetch.IsZero() || time.Now().After(s.expires)
+	s.mu.RUnlock()
+
+	if expired {
+		// stale keys are better than no keys if the refresh fails
+		if err := s.fetch(context.Background(), false); err != nil && keys == nil {
+			return nil, err
+		}
+		keys = s.cachedKeys()
+	}
+	key, err := keys.Lookup(kid)
+	if err == nil || kid == "" {
+		return key, err
+	}
+	// the key might have been rotated, the fetch is skipped if the keys are recent enough
+	if err := s.fetch(context.Background(), false); err != nil {
+		return nil, err
+	}
+	return s.cachedKeys().Lookup(kid)
+}
+
+// Keys returns the key matching the "kid" header
+func (s *RemoteKeySet) Keys(header map[string]interface{}, _ Claims) ([]interface{}, error) {
+	return keysByKeyID(s, header)
+}
+
+// Refresh fetches the keys immediately
+func (s *RemoteKeySet) Refresh(ctx context.Context) error {
+	return s.fetch(ctx, true)
+}
+
+func (s *RemoteKeySet) cachedKeys() KeySet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys
+}
+
+// fetch downloads the JWKS document. Callers waiting for a running fetch share its result.
+// Unless force is set it returns the result of the last fetch if it is more recent than the
+// minimal refresh interval, failed fetches are rate limited as well. On errors the previous
+// keys are kept.
+func (s *RemoteKeySet) fetch(ctx context.Context, force bool) error {
+	started := time.Now()
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	s.mu.RLock()
+	lastFetch, lastErr := s.lastFetch, s.fetchErr
+	s.mu.RUnlock()
+	if lastFetch.After(started) || (!force && !lastFetch.IsZero() && time.Since(lastFetch) < s.minRefreshInterval) {
+		return lastErr
+	}
+
+	keys, ttl, err := s.download(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastFetch, s.fetchErr = time.Now(), err
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.expires = s.lastFetch.Add(ttl)
+	return nil
+}
+
+func (s *RemoteKeySet) download(ctx context.Context) (KeySet, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to fetch jwks: unexpected status %v", resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxJWKSetSize+1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	if len(body) > maxJWKSetSize {
+		return nil, 0, fmt.Errorf("failed to fetch jwks: document exceeds %v bytes", maxJWKSetSize)
+	}
+	set, err := ParseJWKSet(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return set.KeySet(), s.cacheLifetime(resp.Header.Get("Cache-Control")), nil
+}
+
+// cacheLifetime returns how long the keys can be cached according to the Cache-Control header
+func (s *RemoteKeySet) cacheLifetime(cacheControl string) time.Duration {
+	ttl := s.refreshInterval
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			ttl = 0
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if ttl < s.minRefreshInterval {
+		ttl = s.minRefreshInterval
+	}
+	return ttl
+}
+
+// refreshLoop refreshes the keys whenever they expire until the set is closed
+func (s *RemoteKeySet) refreshLoop() {
+	var err error
+	for {
+		s.mu.RLock()
+		wait, fetched := time.Until(s.expires), !s.lastFetch.IsZero()
+		s.mu.RUnlock()
+		if !fetched && err == nil {
+			wait = 0
+		} else if wait < s.minRefreshInterval {
+			wait = s.minRefreshInterval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err = s.fetch(context.Background(), false); err != nil {
+			s.onError(err)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RemoteKeySet", func() {
+
+	var (
+		server       *httptest.Server
+		requests     int32
+		mu           sync.Mutex
+		jwks         []byte
+		cacheControl string
+		status       int
+		rsaJWK       *JWK
+		edPriv       interface{}
+		edJWK        *JWK
+	)
+
+	setKeys := func(keys ...*JWK) {
+		bs, err := json.Marshal(&JWKSet{Keys: keys})
+		Expect(err).NotTo(HaveOccurred())
+		mu.Lock()
+		defer mu.Unlock()
+		jwks = bs
+	}
+
+	BeforeEach(func() {
+		var err error
+		rsaJWK, err = NewJWK(rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		edPriv, err = ParsePrivateKey(ed25519PrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		edJWK, err = NewJWK(edPriv)
+		Expect(err).NotTo(HaveOccurred())
+
+		atomic.StoreInt32(&requests, 0)
+		cacheControl = ""
+		status = http.StatusOK
+		setKeys(rsaJWK.Public())
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			mu.Lock()
+			defer mu.Unlock()
+			if cacheControl != "" {
+				w.Header().Set("Cache-Control", cacheControl)
+			}
+			w.WriteHeader(status)
+			w.Write(jwks)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should fetch the keys and validate tokens", func() {
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithKeyID(rsaJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		for i := 0; i < 3; i++ {
+			reClaims, err := ValidateToken(token, keys)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reClaims).To(Equal(Claims{"foo": "bar"}))
+		}
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+	})
+
+	It("should validate tokens with EdDSA keys which declare their alg", func() {
+		pub := edJWK.Public()
+		pub.Algorithm = "EdDSA"
+		setKeys(pub)
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, edPriv, WithKeyID(edJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should be usable with the ClaimsToContextMiddleware", func() {
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithKeyID(rsaJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		handler := ClaimsToContextMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "", keys)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should refetch the keys for unknown kids, rate limited", func() {
+		keys := NewRemoteKeySet(server.URL, WithMinRefreshInterval(200*time.Millisecond))
+		defer keys.Close()
+		_, err := keys.Lookup(rsaJWK.KeyID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+
+		// the key gets rotated
+		setKeys(rsaJWK.Public(), edJWK.Public())
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, edPriv, WithKeyID(edJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+
+		// too early, the keys have just been fetched
+		_, err = ValidateToken(token, keys)
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+
+		time.Sleep(250 * time.Millisecond)
+		_, err = ValidateToken(token, keys)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+
+		unknown, err := CreateTokenWithOptions(Claims{"foo": "bar"}, edPriv, WithKeyID("unknown"))
+		Expect(err).NotTo(HaveOccurred())
+		for i := 0; i < 5; i++ {
+			_, err = ValidateToken(unknown, keys)
+			Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+		}
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+	})
+
+	It("should refresh in the background respecting Cache-Control", func() {
+		cacheControl = "public, max-age=0"
+		keys := NewRemoteKeySet(server.URL, WithMinRefreshInterval(50*time.Millisecond))
+		defer keys.Close()
+		Eventually(func() int32 { return atomic.LoadInt32(&requests) }).Should(BeNumerically(">=", 3))
+
+		keys.Close()
+		time.Sleep(100 * time.Millisecond)
+		count := atomic.LoadInt32(&requests)
+		Consistently(func() int32 { return atomic.LoadInt32(&requests) }, 200*time.Millisecond).Should(Equal(count))
+	})
+
+	It("should use the max-age of the response as cache lifetime", func() {
+		keys := NewRemoteKeySet(server.URL, WithMinRefreshInterval(time.Second))
+		defer keys.Close()
+		Expect(keys.cacheLifetime("max-age=3600, must-revalidate")).To(Equal(time.Hour))
+		Expect(keys.cacheLifetime("no-store")).To(Equal(time.Second))
+		Expect(keys.cacheLifetime("")).To(Equal(time.Hour))
+	})
+
+	It("should keep the last good keys if a refresh fails", func() {
+		var refreshErrors int32
+		keys := NewRemoteKeySet(server.URL,
+			WithMinRefreshInterval(50*time.Millisecond),
+			WithRefreshInterval(50*time.Millisecond),
+			WithRefreshErrorHandler(func(error) { atomic.AddInt32(&refreshErrors, 1) }))
+		defer keys.Close()
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithKeyID(rsaJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		status = http.StatusInternalServerError
+		mu.Unlock()
+		Eventually(func() int32 { return atomic.LoadInt32(&refreshErrors) }).Should(BeNumerically(">", 0))
+		_, err = ValidateToken(token, keys)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fetch the keys on demand", func() {
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		_, err := keys.Lookup(rsaJWK.KeyID)
+		Expect(err).NotTo(HaveOccurred())
+		setKeys(edJWK.Public())
+		Expect(keys.Refresh(context.Background())).To(Succeed())
+		_, err = keys.Lookup(edJWK.KeyID)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = keys.Lookup(rsaJWK.KeyID)
+		Expect(errors.Is(err, ErrUnknownKeyID)).To(BeTrue())
+	})
+
+	It("should fail if the keys can not be fetched", func() {
+		mu.Lock()
+		status = http.StatusNotFound
+		mu.Unlock()
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithKeyID(rsaJWK.KeyID))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, keys)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should rate limit failed fetches and share running fetches", func() {
+		mu.Lock()
+		status = http.StatusInternalServerError
+		mu.Unlock()
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := keys.Lookup(rsaJWK.KeyID)
+				Expect(err).To(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+	})
+
+	It("should reject oversized documents", func() {
+		mu.Lock()
+		jwks = make([]byte, maxJWKSetSize+1)
+		mu.Unlock()
+		keys := NewRemoteKeySet(server.URL)
+		defer keys.Close()
+		Expect(keys.Refresh(context.Background())).To(MatchError(ContainSubstring("exceeds")))
+	})
+
+})
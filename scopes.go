@@ -0,0 +1,141 @@
+package jwt
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// DefaultScopeClaims are the claims scopes are read from: the space delimited "scope" claim
+	// (RFC 8693) and the "scp" array used by some identity providers
+	DefaultScopeClaims = []string{"scope", "scp"}
+	// DefaultRoleClaims are the claims roles are read from
+	DefaultRoleClaims = []string{"roles"}
+)
+
+// Authorization checks the scopes and roles of the claims stored in the request context by
+// ClaimsToContextMiddleware. The claims can be space delimited strings or arrays of strings.
+// Empty claim lists default to DefaultScopeClaims and DefaultRoleClaims.
+type Authorization struct {
+	ScopeClaims []string
+	RoleClaims  []string
+}
+
+// RequireScopes only passes requests whose token has all of the given scopes
+func RequireScopes(handler http.Handler, scopes ...string) http.Handler {
+	return Authorization{}.RequireScopes(handler, scopes...)
+}
+
+// RequireAnyScope only passes requests whose token has at least one of the given scopes
+func RequireAnyScope(handler http.Handler, scopes ...string) http.Handler {
+	return Authorization{}.RequireAnyScope(handler, scopes...)
+}
+
+// RequireRoles only passes requests whose token has all of the given roles
+func RequireRoles(handler http.Handler, roles ...string) http.Handler {
+	return Authorization{}.RequireRoles(handler, roles...)
+}
+
+// RequireAnyRole only passes requests whose token has at least one of the given roles
+func RequireAnyRole(handler http.Handler, roles ...string) http.Handler {
+	return Authorization{}.RequireAnyRole(handler, roles...)
+}
+
+// RequireScopes only passes requests whose token has all of the given scopes
+func (a Authorization) RequireScopes(handler http.Handler, scopes ...string) http.Handler {
+	return requireValues(handler, "scope", a.scopeClaims(), scopes, true)
+}
+
+// RequireAnyScope only passes requests whose token has at least one of the given scopes
+func (a Authorization) RequireAnyScope(handler http.Handler, scopes ...string) http.Handler {
+	return requireValues(handler, "scope", a.scopeClaims(), scopes, false)
+}
+
+// RequireRoles only passes requests whose token has all of the given roles
+func (a Authorization) RequireRoles(handler http.Handler, roles ...string) http.Handler {
+	return requireValues(handler, "role", a.roleClaims(), roles, true)
+}
+
+// RequireAnyRole only passes requests whose token has at least one of the given roles
+func (a Authorization) RequireAnyRole(handler http.Handler, roles ...string) http.Handler {
+	return requireValues(handler, "role", a.roleClaims(), roles, false)
+}
+
+func (a Authorization) scopeClaims() []string {
+	if len(a.ScopeClaims) == 0 {
+		return DefaultScopeClaims
+	}
+	return a.ScopeClaims
+}
+
+func (a Authorization) roleClaims() []string {
+	if len(a.RoleClaims) == 0 {
+		return DefaultRoleClaims
+	}
+	return a.RoleClaims
+}
+
+// requireValues responds with 403 unless the claims contain all (or any) of the required values.
+// Missing claims in the context are answered with 401 like in RequireClaim. An empty list of
+// required values is most likely a misconfiguration, so all requests are rejected with 403.
+func requireValues(handler http.Handler, kind string, claimNames, required []string, all bool) http.Handler {
+	log := logrus.
+		WithField("require-"+kind, required).
+		WithField("claims", claimNames)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := ClaimsFromContext(r.Context())
+		if claims == nil {
+			msg := "not authorized: failed to get token from context"
+			log.Debug(msg)
+			http.Error(w, msg, http.StatusUnauthorized)
+			return
+		}
+		if len(required) == 0 {
+			msg := fmt.Sprintf("forbidden: no %vs configured", kind)
+			log.Debug(msg)
+			http.Error(w, msg, http.StatusForbidden)
+			return
+		}
+		granted := claimValues(claims, claimNames)
+		var missing []string
+		for _, value := range required {
+			if !containsAny(granted, value) {
+				missing = append(missing, value)
+			}
+		}
+		if len(missing) == 0 || (!all && len(missing) < len(required)) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		msg := fmt.Sprintf("forbidden: missing %v: %v", kind, strings.Join(missing, " "))
+		if !all {
+			msg = fmt.Sprintf("forbidden: requires one of the %vs: %v", kind, strings.Join(required, " "))
+		}
+		log.WithField("granted", granted).Debug(msg)
+		if kind == "scope" {
+			// RFC 6750, section 3.1
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(required, " ")))
+		}
+		http.Error(w, msg, http.StatusForbidden)
+	})
+}
+
+// claimValues collects the values of the given claims, strings are split at whitespace
+func claimValues(claims Claims, names []string) []string {
+	var values []string
+	for _, name := range names {
+		switch v := claims[name].(type) {
+		case string:
+			values = append(values, strings.Fields(v)...)
+		default:
+			if list, ok := stringOrStrings(v); ok {
+				values = append(values, list...)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scope and role middlewares", func() {
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	serve := func(handler http.Handler, claims Claims) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		if claims != nil {
+			r = r.WithContext(ClaimsToContext(r.Context(), claims))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	It("should require all scopes of a space delimited scope claim", func() {
+		handler := RequireScopes(ok, "read", "write")
+		Expect(serve(handler, Claims{"scope": "read write admin"}).Code).To(Equal(http.StatusOK))
+
+		w := serve(handler, Claims{"scope": "read"})
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("missing scope: write"))
+		Expect(w.Header().Get("WWW-Authenticate")).To(Equal(`Bearer error="insufficient_scope", scope="read write"`))
+	})
+
+	It("should read scopes from arrays", func() {
+		handler := RequireScopes(ok, "read", "write")
+		Expect(serve(handler, Claims{"scp": []interface{}{"read", "write"}}).Code).To(Equal(http.StatusOK))
+		Expect(serve(handler, Claims{"scope": "read", "scp": []interface{}{"write"}}).Code).To(Equal(http.StatusOK))
+		Expect(serve(handler, Claims{"scp": []interface{}{"read", 42}}).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require any of the scopes", func() {
+		handler := RequireAnyScope(ok, "read", "admin")
+		Expect(serve(handler, Claims{"scope": "admin"}).Code).To(Equal(http.StatusOK))
+
+		w := serve(handler, Claims{"scope": "write"})
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("requires one of the scopes: read admin"))
+	})
+
+	It("should require roles", func() {
+		Expect(serve(RequireRoles(ok, "admin"), Claims{"roles": []interface{}{"user", "admin"}}).Code).To(Equal(http.StatusOK))
+		Expect(serve(RequireRoles(ok, "admin", "user"), Claims{"roles": "admin"}).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(RequireAnyRole(ok, "admin", "user"), Claims{"roles": "user"}).Code).To(Equal(http.StatusOK))
+		Expect(serve(RequireAnyRole(ok, "admin"), Claims{"scope": "admin"}).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should read the configured claims", func() {
+		auth := Authorization{ScopeClaims: []string{"permissions"}, RoleClaims: []string{"groups"}}
+		Expect(serve(auth.RequireScopes(ok, "read"), Claims{"permissions": []interface{}{"read"}}).Code).To(Equal(http.StatusOK))
+		Expect(serve(auth.RequireScopes(ok, "read"), Claims{"scope": "read"}).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(auth.RequireRoles(ok, "dev"), Claims{"groups": []interface{}{"dev"}}).Code).To(Equal(http.StatusOK))
+	})
+
+	It("should block all requests if no scopes or roles are required", func() {
+		claims := Claims{"scope": "read", "roles": "admin"}
+		for _, handler := range []http.Handler{RequireScopes(ok), RequireAnyScope(ok), RequireRoles(ok), RequireAnyRole(ok, []string{}...)} {
+			w := serve(handler, claims)
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Body.String()).To(ContainSubstring("configured"))
+		}
+	})
+
+	It("should block requests without claims", func() {
+		Expect(serve(RequireScopes(ok, "read"), nil).Code).To(Equal(http.StatusUnauthorized))
+	})
+})
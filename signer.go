@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// signerMethod signs tokens with a crypto.Signer, e.g. a key which never leaves a KMS or an
+// HSM. The algorithm and the verification are those of the embedded signing method.
+type signerMethod struct {
+	jwt.SigningMethod
+}
+
+// Sign hashes the signing string and lets the signer sign the digest
+func (m signerMethod) Sign(signingString string, key interface{}) (string, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	var (
+		digest []byte
+		opts   crypto.SignerOpts
+	)
+	switch method := m.SigningMethod.(type) {
+	case *jwt.SigningMethodRSAPSS:
+		digest = hashSigningString(method.Hash, signingString)
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: method.Hash}
+	case *jwt.SigningMethodRSA:
+		digest, opts = hashSigningString(method.Hash, signingString), method.Hash
+	case *jwt.SigningMethodECDSA:
+		digest, opts = hashSigningString(method.Hash, signingString), method.Hash
+	case *jwt.SigningMethodEd25519:
+		// ed25519 signs the message itself
+		digest, opts = []byte(signingString), crypto.Hash(0)
+	default:
+		return "", fmt.Errorf("%w: can not sign %v with a crypto.Signer", ErrKeyTypeUnsupported, m.Alg())
+	}
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	if method, ok := m.SigningMethod.(*jwt.SigningMethodECDSA); ok {
+		if signature, err = ecdsaSignatureFromDER(signature, method.KeySize); err != nil {
+			return "", err
+		}
+	}
+	return jwt.EncodeSegment(signature), nil
+}
+
+func hashSigningString(hash crypto.Hash, signingString string) []byte {
+	h := hash.New()
+	h.Write([]byte(signingString))
+	return h.Sum(nil)
+}
+
+// ecdsaSignatureFromDER converts the ASN.1 signature returned by crypto.Signer into the
+// fixed size r || s format of JWS (RFC 7518, section 3.4)
+func ecdsaSignatureFromDER(der []byte, keySize int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if err := unmarshalDER(der, &sig); err != nil {
+		return nil, fmt.Errorf("invalid ecdsa signature: %w", err)
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || len(sig.R.Bytes()) > keySize || len(sig.S.Bytes()) > keySize {
+		return nil, errors.New("invalid ecdsa signature: r or s out of range")
+	}
+	out := make([]byte, 2*keySize)
+	copy(out[keySize-len(sig.R.Bytes()):keySize], sig.R.Bytes())
+	copy(out[2*keySize-len(sig.S.Bytes()):], sig.S.Bytes())
+	return out, nil
+}
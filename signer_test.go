@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSigner is an in-process crypto.Signer standing in for a KMS or HSM held key
+type fakeSigner struct {
+	key   crypto.Signer
+	calls int
+	err   error
+}
+
+func (s *fakeSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *fakeSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.key.Sign(rand, digest, opts)
+}
+
+var _ = Describe("crypto.Signer", func() {
+
+	It("should sign tokens with the algorithm of the public key", func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, test := range []struct {
+			key crypto.Signer
+			alg string
+		}{{rsaKey, "RS512"}, {p256Key, "ES256"}, {p521Key, "ES512"}, {edKey, "EdDSA"}} {
+			key, alg := test.key, test.alg
+			signer := &fakeSigner{key: key}
+			token, err := CreateToken(Claims{"foo": "bar"}, signer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signer.calls).To(Equal(1))
+			header, err := getTokenHeader(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(header["alg"]).To(Equal(alg))
+
+			claims, err := ValidateToken(token, key.Public())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims["foo"]).To(Equal("bar"))
+		}
+	})
+
+	It("should sign tokens with the requested algorithm", func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		signer := &fakeSigner{key: rsaKey}
+		for _, alg := range []string{"RS256", "PS256", "PS512"} {
+			token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, signer, WithAlgorithm(alg))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ValidateTokenWithOptions(token, &rsaKey.PublicKey, WithAllowedAlgorithms(alg))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(signer.calls).To(Equal(3))
+
+		_, err = CreateTokenWithOptions(Claims{"foo": "bar"}, signer, WithAlgorithm("ES256"))
+		Expect(errors.Is(err, ErrAlgorithmNotAllowed)).To(BeTrue())
+		Expect(signer.calls).To(Equal(3))
+	})
+
+	It("should return the errors of the signer", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		kmsErr := errors.New("kms unavailable")
+		_, err = CreateToken(Claims{"foo": "bar"}, &fakeSigner{key: key, err: kmsErr})
+		Expect(errors.Is(err, kmsErr)).To(BeTrue())
+	})
+})
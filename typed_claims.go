@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RegisteredClaims are the registered claims of RFC 7519. Embed it in a struct to decode the
+// claims of a token with ValidateTokenInto or GetUnvalidatedClaimsInto.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// NumericDate is a time claim of RFC 7519: the seconds since the epoch, which may have a fraction
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns the NumericDate of t
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t}
+}
+
+// UnmarshalJSON decodes integer and fractional seconds
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var seconds json.Number
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	if i, err := seconds.Int64(); err == nil {
+		d.Time = time.Unix(i, 0)
+		return nil
+	}
+	f, err := seconds.Float64()
+	if err != nil {
+		return err
+	}
+	whole := math.Floor(f)
+	d.Time = time.Unix(int64(whole), int64(math.Round((f-whole)*1e9)))
+	return nil
+}
+
+// MarshalJSON encodes the date as seconds, with a fraction only if needed
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	if d.Nanosecond() == 0 {
+		return []byte(strconv.FormatInt(d.Unix(), 10)), nil
+	}
+	return []byte(strconv.FormatFloat(float64(d.UnixNano())/1e9, 'f', -1, 64)), nil
+}
+
+// Audience is the "aud" claim, which can be a single string or an array of strings
+type Audience []string
+
+// UnmarshalJSON decodes both the string and the array form
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*a = nil
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = Audience(multiple)
+	return nil
+}
+
+// ValidateTokenInto validates the token like ValidateTokenWithOptions and decodes its claims into
+// the struct pointed to by claims
+func ValidateTokenInto(tokenString string, key interface{}, claims interface{}, opts ...ValidationOption) error {
+	return NewValidator(key, opts...).ValidateInto(tokenString, claims)
+}
+
+// ValidateInto validates the token like Validate and decodes its claims into the struct pointed to by
+// claims. The claims are only decoded if the token is valid.
+func (v *Validator) ValidateInto(tokenString string, claims interface{}) error {
+	if _, err := v.Validate(tokenString); err != nil {
+		return err
+	}
+	return decodeClaims(tokenString, claims)
+}
+
+// GetUnvalidatedClaimsInto decodes the token claims into the struct pointed to by claims without
+// validating the token
+func GetUnvalidatedClaimsInto(tokenString string, claims interface{}) error {
+	return decodeClaims(tokenString, claims)
+}
+
+// decodeClaims decodes the claims segment of the token into v
+func decodeClaims(tokenString string, v interface{}) error {
+	claimBytes, err := claimsSegment(tokenString)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(claimBytes, v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("%w: %v", ErrClaimsInvalid, err)
+		}
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+	}
+	return nil
+}
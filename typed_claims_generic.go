@@ -0,0 +1,22 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt
+
+// ValidateTokenAs validates the token like ValidateTokenWithOptions and returns its claims decoded into a T
+func ValidateTokenAs[T any](tokenString string, key interface{}, opts ...ValidationOption) (*T, error) {
+	claims := new(T)
+	if err := ValidateTokenInto(tokenString, key, claims, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GetUnvalidatedClaimsAs returns the token claims decoded into a T without validating the token
+func GetUnvalidatedClaimsAs[T any](tokenString string) (*T, error) {
+	claims := new(T)
+	if err := GetUnvalidatedClaimsInto(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
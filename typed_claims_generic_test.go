@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+package jwt
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generic typed claims", func() {
+
+	It("should return the decoded claims", func() {
+		privKey, err := ParsePrivateKey(ecdsaPrivKey)
+		Expect(err).NotTo(HaveOccurred())
+		pubKey, err := ParsePublicKey(ecdsaPubKey)
+		Expect(err).NotTo(HaveOccurred())
+		token, err := CreateToken(Claims{"sub": "user", "roles": []string{"admin"}}, privKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		claims, err := ValidateTokenAs[testClaims](token, pubKey)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.Subject).To(Equal("user"))
+		Expect(claims.Roles).To(Equal([]string{"admin"}))
+
+		unvalidated, err := GetUnvalidatedClaimsAs[testClaims](token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unvalidated).To(Equal(claims))
+
+		_, err = ValidateTokenAs[testClaims](token, []byte("wrong key"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testClaims struct {
+	RegisteredClaims
+	Roles  []string `json:"roles"`
+	UserID int64    `json:"user_id"`
+}
+
+var _ = Describe("Typed claims", func() {
+
+	It("should decode the claims into a struct", func() {
+		exp := time.Now().Add(time.Hour).Unix()
+		token, err := CreateToken(Claims{
+			"sub":     "user",
+			"aud":     "api",
+			"exp":     exp,
+			"roles":   []string{"admin", "user"},
+			"user_id": int64(9007199254740993),
+		}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+
+		var claims testClaims
+		Expect(ValidateTokenInto(token, rsaPub, &claims, WithAudience("api"))).To(Succeed())
+		Expect(claims.Subject).To(Equal("user"))
+		Expect(claims.Audience).To(Equal(Audience{"api"}))
+		Expect(claims.ExpiresAt.Unix()).To(Equal(exp))
+		Expect(claims.IssuedAt).To(BeNil())
+		Expect(claims.Roles).To(Equal([]string{"admin", "user"}))
+		Expect(claims.UserID).To(Equal(int64(9007199254740993)))
+	})
+
+	It("should decode the array form of the audience", func() {
+		token, err := CreateToken(Claims{"aud": []string{"api", "web"}}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		var claims testClaims
+		Expect(GetUnvalidatedClaimsInto(token, &claims)).To(Succeed())
+		Expect(claims.Audience).To(Equal(Audience{"api", "web"}))
+	})
+
+	It("should decode fractional time claims", func() {
+		exp := float64(time.Now().Add(time.Hour).Unix()) + 0.5
+		token, err := CreateToken(Claims{"exp": exp}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		var claims testClaims
+		Expect(ValidateTokenInto(token, rsaPub, &claims)).To(Succeed())
+		Expect(claims.ExpiresAt.UnixNano()).To(Equal(int64(exp)*int64(time.Second) + int64(time.Second/2)))
+
+		data, err := json.Marshal(claims.RegisteredClaims)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"exp":` + strconv.FormatFloat(exp, 'f', -1, 64)))
+	})
+
+	It("should decode a null audience as no audience", func() {
+		var claims RegisteredClaims
+		Expect(json.Unmarshal([]byte(`{"aud":null,"exp":null}`), &claims)).To(Succeed())
+		Expect(claims.Audience).To(BeEmpty())
+		Expect(claims.ExpiresAt).To(BeNil())
+	})
+
+	It("should validate the time claims before decoding", func() {
+		token, err := CreateToken(Claims{"sub": "user", "exp": time.Now().Add(-time.Hour).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		var claims testClaims
+		err = ValidateTokenInto(token, rsaPub, &claims)
+		Expect(errors.Is(err, ErrTokenExpired)).To(BeTrue())
+		Expect(claims.Subject).To(BeEmpty())
+	})
+
+	It("should report claims of the wrong type", func() {
+		token, err := CreateToken(Claims{"roles": "admin"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		var claims testClaims
+		err = ValidateTokenInto(token, rsaPub, &claims)
+		Expect(errors.Is(err, ErrClaimsInvalid)).To(BeTrue())
+
+		err = GetUnvalidatedClaimsInto("not.a-token.at-all", &claims)
+		Expect(errors.Is(err, ErrTokenMalformed)).To(BeTrue())
+	})
+})
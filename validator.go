@@ -0,0 +1,397 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+)
+
+// ValidationOption configures a Validator
+type ValidationOption func(*Validator)
+
+// Validator checks tokens against a public key and a set of validation rules
+type Validator struct {
+	provider       KeyProvider
+	allowPSS       bool
+	algorithms     []string
+	issuers        []string
+	audiences      []string
+	requireSubject bool
+	leeway         time.Duration
+	now            func() time.Time
+	requireExp     bool
+	requireIat     bool
+	maxLifetime    time.Duration
+	maxAge         time.Duration
+	typ            string
+	useNumber      bool
+}
+
+// NewValidator creates a Validator which checks token signatures with the given public key.
+// If key is a KeyProvider (e.g. a KeySet or a RemoteKeySet) the candidate keys are looked up for every token.
+// A validator without a key rejects all tokens with ErrTokenUnverifiable.
+func NewValidator(key interface{}, opts ...ValidationOption) *Validator {
+	provider, ok := key.(KeyProvider)
+	if !ok && key != nil {
+		provider = StaticKey(key)
+	}
+	v := &Validator{provider: provider, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// AllowRSAPSS makes the validator accept PS256/PS384/PS512 signed tokens when validating
+// with an rsa public key. By default only RS256/RS384/RS512 are accepted.
+func AllowRSAPSS() ValidationOption {
+	return func(v *Validator) {
+		v.allowPSS = true
+	}
+}
+
+// WithAllowedAlgorithms pins the exact set of "alg" header values the validator accepts.
+// Tokens using any other algorithm (including "none") are rejected with ErrAlgorithmNotAllowed.
+// Listing a PS algorithm implies AllowRSAPSS.
+func WithAllowedAlgorithms(algs ...string) ValidationOption {
+	return func(v *Validator) {
+		v.algorithms = append(v.algorithms, algs...)
+	}
+}
+
+// WithIssuer requires the "iss" claim to be one of the given issuers
+func WithIssuer(issuers ...string) ValidationOption {
+	return func(v *Validator) {
+		v.issuers = append(v.issuers, issuers...)
+	}
+}
+
+// WithAudience requires the "aud" claim to contain at least one of the given audiences.
+// Both the string and the array form of "aud" are supported.
+func WithAudience(audiences ...string) ValidationOption {
+	return func(v *Validator) {
+		v.audiences = append(v.audiences, audiences...)
+	}
+}
+
+// RequireSubject requires the token to have a non-empty "sub" claim
+func RequireSubject() ValidationOption {
+	return func(v *Validator) {
+		v.requireSubject = true
+	}
+}
+
+// WithLeeway allows for the given clock skew when checking the "exp", "nbf" and "iat" claims
+func WithLeeway(leeway time.Duration) ValidationOption {
+	return func(v *Validator) {
+		v.leeway = leeway
+	}
+}
+
+// WithClock sets the function used to get the current time when checking time based claims
+func WithClock(now func() time.Time) ValidationOption {
+	return func(v *Validator) {
+		v.now = now
+	}
+}
+
+// RequireExpiry rejects tokens without an "exp" claim with ErrMissingExpiry
+func RequireExpiry() ValidationOption {
+	return func(v *Validator) {
+		v.requireExp = true
+	}
+}
+
+// RequireIssuedAt rejects tokens without an "iat" claim with ErrMissingIssuedAt
+func RequireIssuedAt() ValidationOption {
+	return func(v *Validator) {
+		v.requireIat = true
+	}
+}
+
+// WithMaxLifetime rejects tokens whose lifetime (exp - iat) exceeds max with ErrLifetimeTooLong.
+// It implies RequireExpiry and RequireIssuedAt.
+func WithMaxLifetime(max time.Duration) ValidationOption {
+	return func(v *Validator) {
+		v.maxLifetime = max
+		v.requireExp = true
+		v.requireIat = true
+	}
+}
+
+// WithMaxAge rejects tokens which have been issued more than max ago (now - iat) with ErrTokenTooOld.
+// It implies RequireIssuedAt.
+func WithMaxAge(max time.Duration) ValidationOption {
+	return func(v *Validator) {
+		v.maxAge = max
+		v.requireIat = true
+	}
+}
+
+// WithExpectedType requires the "typ" header of the token to match typ, e.g. "at+jwt".
+// The comparison ignores case and an "application/" prefix as specified by RFC 7515, section 4.1.9.
+func WithExpectedType(typ string) ValidationOption {
+	return func(v *Validator) {
+		v.typ = typ
+	}
+}
+
+// UseJSONNumber decodes the numbers of the claims as json.Number instead of float64, so large
+// integers (e.g. ids) keep their precision. Use Claims.GetInt64 or GetFloat64 to convert them.
+func UseJSONNumber() ValidationOption {
+	return func(v *Validator) {
+		v.useNumber = true
+	}
+}
+
+// Validate checks the signature of the token and returns the associated claims
+func (v *Validator) Validate(tokenString string) (Claims, error) {
+	if v.provider == nil {
+		return nil, fmt.Errorf("%w: the validator has no key", ErrTokenUnverifiable)
+	}
+	parser := &jwt.Parser{SkipClaimsValidation: true, UseJSONNumber: v.useNumber}
+	unverified, _, err := parser.ParseUnverified(tokenString, jwt.MapClaims{})
+	if unverified != nil && unverified.Header != nil {
+		if alg, _ := unverified.Header["alg"].(string); !v.algorithmAllowed(alg) {
+			return nil, fmt.Errorf("%w: %q", ErrAlgorithmNotAllowed, alg)
+		}
+	}
+	if err != nil {
+		return nil, wrapValidationError(err)
+	}
+
+	keys, err := v.provider.Keys(unverified.Header, Claims(unverified.Claims.(jwt.MapClaims)))
+	if err == nil && len(keys) == 0 {
+		err = fmt.Errorf("%w: no key found for token", ErrUnknownKeyID)
+	}
+	if err != nil {
+		return nil, wrapValidationError(&jwt.ValidationError{Inner: err, Errors: jwt.ValidationErrorUnverifiable})
+	}
+
+	// try all candidate keys fitting to the signing method, the first valid signature wins
+	var lastErr error
+	for _, key := range keys {
+		if err := v.checkSigningMethod(unverified.Method, key); err != nil {
+			if lastErr == nil {
+				lastErr = &jwt.ValidationError{Inner: err, Errors: jwt.ValidationErrorUnverifiable}
+			}
+			continue
+		}
+		if pinned, ok := key.(PinnedKey); ok {
+			key = pinned.Key
+		}
+		token, err := parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return v.validateToken(token)
+	}
+	return nil, wrapValidationError(lastErr)
+}
+
+// validateToken checks the header and claims of a token with a valid signature
+func (v *Validator) validateToken(token *jwt.Token) (Claims, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected claims type %T", ErrTokenMalformed, token.Claims)
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("%w: token was not verified", ErrSignatureInvalid)
+	}
+	if err := v.validateHeader(token.Header); err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(Claims(claims)); err != nil {
+		return nil, err
+	}
+	return Claims(claims), nil
+}
+
+// validateHeader checks the header fields of a token with a valid signature
+func (v *Validator) validateHeader(header map[string]interface{}) error {
+	if v.typ != "" {
+		typ, _ := header["typ"].(string)
+		if normalizeType(typ) != normalizeType(v.typ) {
+			return fmt.Errorf("%w: %q", ErrInvalidType, typ)
+		}
+	}
+	return nil
+}
+
+// normalizeType strips the optional "application/" prefix of a media type and lowercases it (RFC 7515, section 4.1.9)
+func normalizeType(typ string) string {
+	typ = strings.ToLower(typ)
+	return strings.TrimPrefix(typ, "application/")
+}
+
+// validateClaims checks the registered claims of a token with a valid signature
+func (v *Validator) validateClaims(claims Claims) error {
+	if err := v.validateTimes(claims); err != nil {
+		return wrapValidationError(err)
+	}
+	if len(v.issuers) > 0 {
+		iss, _ := claims["iss"].(string)
+		if !containsAny(v.issuers, iss) {
+			return fmt.Errorf("%w: %q", ErrInvalidIssuer, iss)
+		}
+	}
+	if len(v.audiences) > 0 {
+		aud, ok := stringOrStrings(claims["aud"])
+		if !ok || !containsAny(v.audiences, aud...) {
+			return fmt.Errorf("%w: %v", ErrInvalidAudience, claims["aud"])
+		}
+	}
+	if v.requireSubject {
+		if sub, _ := claims["sub"].(string); sub == "" {
+			return ErrMissingSubject
+		}
+	}
+	return nil
+}
+
+// checkSigningMethod makes sure that the signing method of a token fits to the given public key
+func (v *Validator) checkSigningMethod(method jwt.SigningMethod, key interface{}) error {
+	switch k := key.(type) {
+	case PinnedKey:
+		if method.Alg() != k.Algorithm {
+			return fmt.Errorf("unexpected signing method %v for a %v key: %w", method.Alg(), k.Algorithm, ErrAlgorithmNotAllowed)
+		}
+		if _, ok := k.Key.(*rsa.PublicKey); ok {
+			// a key pinned to a PS algorithm allows RSA-PSS
+			return nil
+		}
+		return v.checkSigningMethod(method, k.Key)
+	case *rsa.PublicKey:
+		switch method.(type) {
+		case *jwt.SigningMethodRSA:
+			return nil
+		case *jwt.SigningMethodRSAPSS:
+			if v.allowPSS || len(v.algorithms) > 0 {
+				return nil
+			}
+		}
+	case *ecdsa.PublicKey:
+		expected, err := ecdsaSigningMethod(k)
+		if err != nil {
+			return err
+		}
+		if method.Alg() == expected.Alg() {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if _, ok := method.(*jwt.SigningMethodEd25519); ok {
+			return nil
+		}
+	case []byte:
+		if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+			return nil
+		}
+	default:
+		return fmt.Errorf("invalid public key: %w", ErrKeyTypeUnsupported)
+	}
+	return fmt.Errorf("unexpected signing method %v: %w", method.Alg(), ErrAlgorithmNotAllowed)
+}
+
+// validateTimes checks the "exp", "iat" and "nbf" claims against the validators clock, allowing for the configured leeway.
+// The returned errors use the same flags as the jwt library.
+func (v *Validator) validateTimes(claims Claims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+	exp, expPresent, ok := timeClaim(claims, "exp")
+	if expPresent && (!ok || now.After(exp.Add(v.leeway))) {
+		return jwt.NewValidationError("Token is expired", jwt.ValidationErrorExpired)
+	}
+	iat, iatPresent, ok := timeClaim(claims, "iat")
+	if iatPresent && (!ok || now.Add(v.leeway).Before(iat)) {
+		return jwt.NewValidationError("Token used before issued", jwt.ValidationErrorIssuedAt)
+	}
+	if nbf, present, ok := timeClaim(claims, "nbf"); present && (!ok || now.Add(v.leeway).Before(nbf)) {
+		return jwt.NewValidationError("Token is not valid yet", jwt.ValidationErrorNotValidYet)
+	}
+	if v.requireExp && !expPresent {
+		return ErrMissingExpiry
+	}
+	if v.requireIat && !iatPresent {
+		return ErrMissingIssuedAt
+	}
+	if v.maxLifetime > 0 && exp.Sub(iat) > v.maxLifetime {
+		return fmt.Errorf("%w: %v > %v", ErrLifetimeTooLong, exp.Sub(iat), v.maxLifetime)
+	}
+	if v.maxAge > 0 && now.Sub(iat) > v.maxAge+v.leeway {
+		return fmt.Errorf("%w: issued %v ago", ErrTokenTooOld, now.Sub(iat).Round(time.Second))
+	}
+	return nil
+}
+
+// timeClaim reads a NumericDate claim. present is false if the claim is not set, ok is false if it has the wrong type.
+func timeClaim(claims Claims, name string) (t time.Time, present bool, ok bool) {
+	value, present := claims[name]
+	if !present {
+		return t, false, false
+	}
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true, true
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return t, true, false
+			}
+			i = int64(f)
+		}
+		return time.Unix(i, 0), true, true
+	}
+	return t, true, false
+}
+
+// algorithmAllowed reports whether alg is in the allowlist. Without an allowlist every algorithm
+// passes and only the key type decides.
+func (v *Validator) algorithmAllowed(alg string) bool {
+	return len(v.algorithms) == 0 || containsAny(v.algorithms, alg)
+}
+
+// containsAny reports whether one of the values is contained in list
+func containsAny(list []string, values ...string) bool {
+	for _, l := range list {
+		for _, v := range values {
+			if l == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringOrStrings converts a claim which is either a string or an array of strings to a slice
+func stringOrStrings(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			str, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			res = append(res, str)
+		}
+		return res, true
+	}
+	return nil, false
+}
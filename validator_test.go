@@ -0,0 +1,292 @@
+package jwt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validator", func() {
+
+	It("should reject RSA-PSS tokens unless explicitly allowed", func() {
+		claims := Claims{"foo": "bar"}
+		for _, alg := range []string{"PS256", "PS384", "PS512"} {
+			token, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm(alg))
+			Expect(err).NotTo(HaveOccurred())
+			header, err := getTokenHeader(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(header["alg"]).To(Equal(alg))
+
+			_, err = ValidateToken(token, rsaPub)
+			Expect(err).To(HaveOccurred())
+
+			reClaims, err := ValidateTokenWithOptions(token, rsaPub, AllowRSAPSS())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reClaims).To(Equal(claims))
+		}
+	})
+
+	It("should still accept RS tokens when RSA-PSS is allowed", func() {
+		claims := Claims{"foo": "bar"}
+		token, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("RS256"))
+		Expect(err).NotTo(HaveOccurred())
+		reClaims, err := ValidateTokenWithOptions(token, rsaPub, AllowRSAPSS())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(Equal(claims))
+	})
+
+	It("should NOT verify a PSS signature as PKCS#1 v1.5 signature and vice versa", func() {
+		claims := Claims{"foo": "bar"}
+		psToken, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("PS256"))
+		Expect(err).NotTo(HaveOccurred())
+		rsToken, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("RS256"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = ValidateTokenWithOptions(swapAlg(psToken, "RS256"), rsaPub, AllowRSAPSS())
+		Expect(err).To(HaveOccurred())
+		_, err = ValidateTokenWithOptions(swapAlg(rsToken, "PS256"), rsaPub, AllowRSAPSS())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only accept algorithms from the allowlist", func() {
+		claims := Claims{"foo": "bar"}
+		rs512Token, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("RS512"))
+		Expect(err).NotTo(HaveOccurred())
+		rs256Token, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("RS256"))
+		Expect(err).NotTo(HaveOccurred())
+
+		validator := NewValidator(rsaPub, WithAllowedAlgorithms("RS512"))
+		reClaims, err := validator.Validate(rs512Token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(Equal(claims))
+
+		reClaims, err = validator.Validate(rs256Token)
+		Expect(errors.Is(err, ErrAlgorithmNotAllowed)).To(BeTrue())
+		Expect(reClaims).To(BeEmpty())
+	})
+
+	It("should reject unsigned tokens with ErrAlgorithmNotAllowed", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		parts := strings.Split(swapAlg(token, "none"), ".")
+		parts[2] = ""
+		_, err = ValidateTokenWithOptions(strings.Join(parts, "."), rsaPub, WithAllowedAlgorithms("RS512"))
+		Expect(errors.Is(err, ErrAlgorithmNotAllowed)).To(BeTrue())
+	})
+
+	It("should accept RSA-PSS tokens if their algorithm is in the allowlist", func() {
+		claims := Claims{"foo": "bar"}
+		token, err := CreateTokenWithOptions(claims, rsaPriv, WithAlgorithm("PS384"))
+		Expect(err).NotTo(HaveOccurred())
+		reClaims, err := ValidateTokenWithOptions(token, rsaPub, WithAllowedAlgorithms("PS384"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reClaims).To(Equal(claims))
+	})
+
+	It("should validate the issuer", func() {
+		token, err := CreateToken(Claims{"iss": "https://idp.example.com"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithIssuer("https://other.example.com", "https://idp.example.com"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithIssuer("https://other.example.com"))
+		Expect(errors.Is(err, ErrInvalidIssuer)).To(BeTrue())
+
+		token, err = CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithIssuer("https://idp.example.com"))
+		Expect(errors.Is(err, ErrInvalidIssuer)).To(BeTrue())
+	})
+
+	It("should validate the audience in string and array form", func() {
+		for _, aud := range []interface{}{"api", []string{"web", "api"}} {
+			token, err := CreateToken(Claims{"aud": aud}, rsaPriv)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ValidateTokenWithOptions(token, rsaPub, WithAudience("api"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ValidateTokenWithOptions(token, rsaPub, WithAudience("admin"))
+			Expect(errors.Is(err, ErrInvalidAudience)).To(BeTrue())
+		}
+
+		token, err := CreateToken(Claims{"aud": 42}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithAudience("42"))
+		Expect(errors.Is(err, ErrInvalidAudience)).To(BeTrue())
+	})
+
+	It("should require a subject if configured", func() {
+		token, err := CreateToken(Claims{"sub": "user-1"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireSubject())
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err = CreateToken(Claims{"sub": ""}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireSubject())
+		Expect(errors.Is(err, ErrMissingSubject)).To(BeTrue())
+	})
+
+	It("should validate time based claims against an injected clock", func() {
+		now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		clock := func() time.Time { return now }
+		token, err := CreateToken(Claims{"iat": now.Unix(), "nbf": now.Unix(), "exp": now.Add(time.Minute).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithClock(clock))
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(2 * time.Minute)
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithClock(clock))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Token is expired"))
+
+		now = now.Add(-time.Hour)
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithClock(clock))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Token used before issued"))
+	})
+
+	It("should allow for clock skew with a leeway", func() {
+		now := time.Now()
+		token, err := CreateToken(Claims{"iat": now.Add(5 * time.Second).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(err).To(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithLeeway(10*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err = CreateToken(Claims{"nbf": now.Add(5 * time.Second).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(err).To(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithLeeway(10*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err = CreateToken(Claims{"exp": now.Add(-5 * time.Second).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(err).To(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithLeeway(10*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should NOT accept time based claims with a wrong type", func() {
+		token, err := CreateToken(Claims{"exp": "tomorrow"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateToken(token, rsaPub)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should require exp and iat if configured", func() {
+		now := time.Now()
+		token, err := CreateToken(Claims{"iat": now.Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireIssuedAt())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireExpiry())
+		Expect(errors.Is(err, ErrMissingExpiry)).To(BeTrue())
+
+		token, err = CreateToken(Claims{"exp": now.Add(time.Minute).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireExpiry())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, RequireIssuedAt())
+		Expect(errors.Is(err, ErrMissingIssuedAt)).To(BeTrue())
+	})
+
+	It("should enforce a maximum token lifetime", func() {
+		now := time.Now()
+		token, err := CreateToken(Claims{"iat": now.Unix(), "exp": now.Add(24 * time.Hour).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithMaxLifetime(48*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithMaxLifetime(time.Hour))
+		Expect(errors.Is(err, ErrLifetimeTooLong)).To(BeTrue())
+
+		token, err = CreateToken(Claims{"iat": now.Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithMaxLifetime(time.Hour))
+		Expect(errors.Is(err, ErrMissingExpiry)).To(BeTrue())
+	})
+
+	It("should enforce a maximum token age", func() {
+		now := time.Now()
+		token, err := CreateToken(Claims{"iat": now.Add(-2 * time.Hour).Unix()}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithMaxAge(3*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithMaxAge(time.Hour))
+		Expect(errors.Is(err, ErrTokenTooOld)).To(BeTrue())
+	})
+
+	It("should set custom header fields and validate the token type", func() {
+		token, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithType("at+jwt"), WithHeader("cty", "json"), WithHeader("x-tenant", 42))
+		Expect(err).NotTo(HaveOccurred())
+		header, err := getTokenHeader(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(header).To(HaveKeyWithValue("typ", "at+jwt"))
+		Expect(header).To(HaveKeyWithValue("cty", "json"))
+		Expect(header).To(HaveKeyWithValue("x-tenant", float64(42)))
+
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithExpectedType("at+jwt"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(token, rsaPub, WithExpectedType("application/AT+JWT"))
+		Expect(err).NotTo(HaveOccurred())
+
+		idToken, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ValidateTokenWithOptions(idToken, rsaPub, WithExpectedType("at+jwt"))
+		Expect(errors.Is(err, ErrInvalidType)).To(BeTrue())
+	})
+
+	It("should NOT be possible to override the alg header", func() {
+		_, err := CreateTokenWithOptions(Claims{"foo": "bar"}, rsaPriv, WithHeader("alg", "none"))
+		Expect(errors.Is(err, ErrInvalidHeader)).To(BeTrue())
+	})
+
+	It("should reject all tokens if the validator has no key", func() {
+		token, err := CreateToken(Claims{"foo": "bar"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+		for _, validator := range []*Validator{{}, NewValidator(nil)} {
+			_, err = validator.Validate(token)
+			Expect(errors.Is(err, ErrTokenUnverifiable)).To(BeTrue())
+		}
+	})
+
+	It("should be possible to use a validator in the ClaimsToContextMiddleware", func() {
+		handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(ClaimsFromContext(r.Context())).To(HaveKeyWithValue("iss", "idp"))
+		}))
+		token, err := CreateToken(Claims{"iss": "idp"}, rsaPriv)
+		Expect(err).NotTo(HaveOccurred())
+
+		handlerA := ClaimsToContextMiddlewareWithValidator(handler, "", NewValidator(rsaPub, WithIssuer("idp")))
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "bearer "+token)
+		w := httptest.NewRecorder()
+		handlerA.ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		handlerB := ClaimsToContextMiddlewareWithValidator(handler, "", NewValidator(rsaPub, WithIssuer("other")))
+		w = httptest.NewRecorder()
+		handlerB.ServeHTTP(w, r)
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+		w = httptest.NewRecorder()
+		handlerA.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+})
+
+// swapAlg replaces the header of a token with a header containing only the given alg
+func swapAlg(token, alg string) string {
+	parts := strings.Split(token, ".")
+	parts[0] = jwt.EncodeSegment([]byte(`{"alg":"` + alg + `","typ":"JWT"}`))
+	return strings.Join(parts, ".")
+}